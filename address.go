@@ -0,0 +1,99 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+
+/**
+ * Street suffixes normalized by AddressMatch, mapping each spelling in
+ * lower case and without any trailing period to one form.
+ */
+var StreetSuffixes = map[string]string{
+	"st": "street", "str": "street", "street": "street",
+	"ave": "avenue", "av": "avenue", "avenue": "avenue",
+	"rd": "road", "road": "road",
+	"blvd": "boulevard", "boulevard": "boulevard",
+	"dr": "drive", "drive": "drive",
+	"ln": "lane", "lane": "lane",
+	"ct": "court", "court": "court",
+	"pl": "place", "place": "place",
+	"sq": "square", "square": "square",
+	"hwy": "highway", "highway": "highway",
+	"pkwy": "parkway", "parkway": "parkway",
+	"ter": "terrace", "terrace": "terrace",
+}
+
+
+/**
+ * Split an address into its lower case tokens of letters and digits,
+ * normalizing street suffixes by StreetSuffixes and coding words by
+ * enc. Tokens holding a digit, such as "123" or "4b", are kept. Words
+ * in other scripts are transliterated first (see Transliterate), and
+ * a word enc gives a blank code is kept as it is.
+ */
+func addressTokens(address string, enc Encoder) []string {
+	
+	words := strings.FieldsFunc(strings.ToLower(address), func(c int) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	
+	for i, word := range words {
+		if strings.IndexAny(word, "0123456789") >= 0 {
+			continue
+		}
+		if suffix, ok := StreetSuffixes[word]; ok {
+			word = suffix
+		}
+		if code := enc.Encode(Transliterate(word)); code != "" {
+			words[i] = code
+		}
+	}
+	
+	return words
+}
+
+
+/**
+ * Whether addresses a and b match: their numeric tokens must be equal,
+ * and their words sound alike by Soundex once street suffixes are
+ * normalized, so "123 Main St" matches "123 Main Street" but not
+ * "125 Main Street". See AddressMatchWith to use another encoder.
+ */
+func AddressMatch(a, b string) bool {
+	return AddressMatchWith(a, b, SoundexEncoder)
+}
+
+
+/**
+ * As AddressMatch, but comparing words by their codes under enc. Words
+ * without a code must be spelled the same.
+ */
+func AddressMatchWith(a, b string, enc Encoder) bool {
+	
+	ta, tb := addressTokens(a, enc), addressTokens(b, enc)
+	
+	if len(ta) != len(tb) {
+		return false
+	}
+	
+	for i := range ta {
+		if ta[i] != tb[i] {
+			return false
+		}
+	}
+	
+	return true
+}
@@ -0,0 +1,40 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestAddressMatch(t *testing.T) {
+	checkState(t, AddressMatch("123 Main St", "123 Main Street"), "suffix should be normalized")
+	checkState(t, AddressMatch("42 Elm Ave.", "42 elm avenue"), "should ignore case and periods")
+	checkState(t, AddressMatch("7 Smith Rd", "7 Smyth Road"), "words should match phonetically")
+	checkState(t, AddressMatch("4B Baker St", "4b Baker Street"), "mixed tokens should ignore case")
+	checkState(t, !AddressMatch("123 Main St", "125 Main Street"), "numbers should match exactly")
+	checkState(t, !AddressMatch("123 Main St", "123 Main Ave"), "suffixes should still differ")
+	checkState(t, !AddressMatch("123 Main St", "123 Main St Apt 4"), "extra tokens should not match")
+}
+
+func TestAddressMatchScripts(t *testing.T) {
+	checkState(t, !AddressMatch("1 Москва", "1 Киев"), "different Cyrillic names should not match")
+	checkState(t, AddressMatch("1 Москва", "1 Moskva"), "should transliterate before coding")
+	checkState(t, !AddressMatch("1 東京", "1 大阪"), "uncoded words should compare literally")
+	checkState(t, AddressMatch("1 東京", "1 東京"), "the same uncoded word should match")
+}
+
+func TestAddressMatchWith(t *testing.T) {
+	checkState(t, AddressMatchWith("7 Smith Rd", "7 Smyth Road", MetaphoneEncoder), "should code words by the encoder")
+	checkState(t, AddressMatch("9 Robert St", "9 Rupert St"), "Soundex should match Robert and Rupert")
+	checkState(t, !AddressMatchWith("9 Robert St", "9 Rupert St", MetaphoneEncoder), "Metaphone should not")
+}
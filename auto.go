@@ -0,0 +1,187 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+
+// Letter clusters which are rare in English names but common in German.
+var germanMarkers = []string{"sch", "tz", "pf", "dt", "ä", "ö", "ü", "ß"}
+
+
+/**
+ * Guess the dominant script of s by counting its letters.
+ * Returns "latin", "cyrillic", "greek", or "unknown" when s has no
+ * letters from any of those.
+ */
+func DetectScript(s string) string {
+	
+	latin, cyrillic, greek := 0, 0, 0
+	
+	for _, c := range s {
+		switch {
+		case unicode.Is(unicode.Latin, c):
+			latin++
+		case unicode.Is(unicode.Cyrillic, c):
+			cyrillic++
+		case unicode.Is(unicode.Greek, c):
+			greek++
+		}
+	}
+	
+	switch {
+	case latin == 0 && cyrillic == 0 && greek == 0:
+		return "unknown"
+	case latin >= cyrillic && latin >= greek:
+		return "latin"
+	case cyrillic >= greek:
+		return "cyrillic"
+	}
+	return "greek"
+}
+
+
+func looksGerman(s string) bool {
+	s = strings.ToLower(s)
+	for _, m := range germanMarkers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
+
+
+/**
+ * Pick the encoder best suited to name: Cologne phonetics for
+ * German-looking names, Soundex for other Latin names. Returns nil if
+ * no encoder in this package handles the script of name.
+ */
+func DetectEncoder(name string) Encoder {
+	if DetectScript(name) != "latin" {
+		return nil
+	}
+	if looksGerman(name) {
+		return CologneEncoder
+	}
+	return SoundexEncoder
+}
+
+
+/**
+ * Encode name with the encoder chosen by DetectEncoder, or return ""
+ * if none applies.
+ */
+func EncodeAuto(name string) string {
+	enc := DetectEncoder(name)
+	if enc == nil {
+		return ""
+	}
+	return enc.Encode(name)
+}
+
+
+// The share of a sample which must show a kind of error for
+// RecommendEncoder to pick an encoder suited to it.
+const recommendShare = 0.2
+
+
+// Characters OCR commonly reads in place of letters, e.g. 0 for O.
+var ocrConfusables = "0123456789|!$@"
+
+
+/**
+ * Whether name looks misread by OCR: it mixes letters with characters
+ * that are easily confused with them.
+ */
+func looksOCR(name string) bool {
+	letters, confusables := false, false
+	for _, c := range name {
+		if unicode.IsLetter(c) {
+			letters = true
+		} else if strings.IndexRune(ocrConfusables, c) >= 0 {
+			confusables = true
+		}
+	}
+	return letters && confusables
+}
+
+
+/**
+ * Whether name looks mistyped: some word of three or more letters has
+ * no vowel, or some letter is typed three times running.
+ */
+func looksTypo(name string) bool {
+	for _, word := range strings.Fields(strings.ToLower(name)) {
+		vowel, run, letters := false, 0, 0
+		var prev int
+		for _, c := range word {
+			if !unicode.IsLetter(c) {
+				continue
+			}
+			letters++
+			if strings.IndexRune("aeiouy", c) >= 0 {
+				vowel = true
+			}
+			if c == prev {
+				run++
+			} else {
+				run = 1
+			}
+			if run == 3 {
+				return true
+			}
+			prev = c
+		}
+		if letters >= 3 && !vowel {
+			return true
+		}
+	}
+	return false
+}
+
+
+/**
+ * Suggest an encoder for data like sample: Fuzzy Soundex when many names
+ * look misread by OCR, Metaphone when many look mistyped, and Soundex
+ * for genuine spelling variants otherwise. This is a heuristic; "many"
+ * means at least recommendShare of the sample.
+ */
+func RecommendEncoder(sample []string) Encoder {
+	
+	if len(sample) == 0 {
+		return SoundexEncoder
+	}
+	
+	ocr, typos := 0, 0
+	
+	for _, name := range sample {
+		if looksOCR(name) {
+			ocr++
+		} else if looksTypo(name) {
+			typos++
+		}
+	}
+	
+	n := float64(len(sample))
+	
+	switch {
+	case float64(ocr)/n >= recommendShare:
+		return FuzzySoundexEncoder
+	case float64(typos)/n >= recommendShare:
+		return MetaphoneEncoder
+	}
+	return SoundexEncoder
+}
@@ -0,0 +1,45 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestDetectScript(t *testing.T) {
+	checkString(t, DetectScript("Robin"), "latin", "should be latin")
+	checkString(t, DetectScript("Иванов"), "cyrillic", "should be cyrillic")
+	checkString(t, DetectScript("Παπαδόπουλος"), "greek", "should be greek")
+	checkString(t, DetectScript("1234"), "unknown", "should be unknown")
+}
+
+func TestEncodeAuto(t *testing.T) {
+	checkString(t, DetectEncoder("Schwarz").Name(), "cologne", "german name should use cologne")
+	checkString(t, DetectEncoder("Robert").Name(), "soundex", "english name should use soundex")
+	checkString(t, EncodeAuto("Schwarz"), ColognePhonetic("Schwarz"), "should encode with cologne")
+	checkString(t, EncodeAuto("Robert"), Soundex("Robert", 4), "should encode with soundex")
+	checkState(t, DetectEncoder("Иванов") == nil, "no encoder for cyrillic")
+	checkString(t, EncodeAuto("Иванов"), "", "should not encode cyrillic")
+}
+
+func TestRecommendEncoder(t *testing.T) {
+	ocr := []string{"J0hn Smith", "5mith", "Wi11iams", "Robert", "Br0wn"}
+	checkString(t, RecommendEncoder(ocr).Name(), "fuzzysoundex", "OCR-like sample should use fuzzy soundex")
+
+	typos := []string{"Jhn Smith", "Robert", "Wiiilliams", "Brown", "Lee"}
+	checkString(t, RecommendEncoder(typos).Name(), "metaphone", "mistyped sample should use metaphone")
+
+	clean := []string{"John Smith", "Smyth", "Williams", "Robert", "Brown"}
+	checkString(t, RecommendEncoder(clean).Name(), "soundex", "clean sample should use soundex")
+	checkString(t, RecommendEncoder(nil).Name(), "soundex", "empty sample should use soundex")
+}
@@ -13,54 +13,55 @@ package phonetic
 
 import (
 	"strings"
-	"regexp"
-	//"fmt"
-	"sre2"
 )
 
 
+// CaverphoneOpts tunes the output of CaverphoneWithOpts.
+type CaverphoneOpts struct {
+	// Keep the "2" (silent) and "3" (vowel) markers in the code rather
+	// than stripping them, and don't cut it to ten characters. This
+	// keeps the syllable structure, giving a longer code.
+	KeepMarkers bool
+	
+	// Pad or cut the code to this many characters rather than ten. A
+	// marked code is still only padded, never cut. Zero means ten.
+	Length int
+}
+
+
 /**
  * This is Caverphone algorithm version 2.0
  * based on paper: http://caversham.otago.ac.nz/files/working/ctp150804.pdf
  */
 func Caverphone(text string) string {
+	return CaverphoneWithOpts(text, CaverphoneOpts{})
+}
 
-	rv := ""
-	
-	length := len(text)
-	
-	if length == 0 {
-		return rv
-	}
-	
-	rv = strings.ToLower(text)
-	
-	re, e := regexp.Compile("[^a-z]")
-	if e != nil {
-		return rv
-	}
-	
-	// remove non alphabet char
-	rv = re.ReplaceAllString(rv, "")
-	
-	re2 := sre2.MustParse("^([crt]|(en)|(tr))ough")
-	
-	if caps := re2.Extract(rv, 3); len(caps) > 1 {
-		d := caps[1] + "ou2f"
-		l := len(d)
-		rv = d + rv[l:]
+
+/**
+ * Caverphone 2.0, tuned by opts. Any text gives a full code: one with
+ * no letters, e.g. "" or ".", codes as all ones.
+ */
+func CaverphoneWithOpts(text string, opts CaverphoneOpts) string {
+
+	// lower case, and remove non alphabet char; what's left may be empty,
+	// which codes as all ones
+	rv := lowerAlpha(text)
+	
+	for _, p := range []string{"cough", "rough", "tough", "enough", "trough"} {
+		if strings.HasPrefix(rv, p) {
+			l := len(p) - 2
+			rv = rv[:l] + "2f" + rv[l+2:]
+			break
+		}
 	}
 	
-	re2 = sre2.MustParse("^gn")
-	
-	if match := re2.Match(rv); match {
+	if strings.HasPrefix(rv, "gn") {
 		rv = "2n" + rv[2:]
 	}
 	
-	re2 = sre2.MustParse("mb$")
-	
-	if match := re2.Match(rv); match {
-		rv = rv[:-2] + "m2"
+	if strings.HasSuffix(rv, "mb") {
+		rv = rv[:len(rv)-2] + "m2"
 	}
 	
 	rv = strings.Replace(rv, "cq", "2q", -1)
@@ -68,93 +69,242 @@ func Caverphone(text string) string {
 	rv = strings.Replace(rv, "ce", "se", -1)
 	rv = strings.Replace(rv, "cy", "sy", -1)
 	rv = strings.Replace(rv, "tch", "2ch", -1)
-	rv = strings.Replace(rv, "c", "k", -1)
-	rv = strings.Replace(rv, "q", "k", -1)
-	rv = strings.Replace(rv, "x", "k", -1)
-	rv = strings.Replace(rv, "v", "f", -1)
+	rv = translate(rv, "cqxv", "kkkf")
 	rv = strings.Replace(rv, "dg", "2g", -1)
 	rv = strings.Replace(rv, "tio", "sio", -1)
 	rv = strings.Replace(rv, "tia", "sia", -1)
-	rv = strings.Replace(rv, "d", "t", -1)
+	rv = translate(rv, "d", "t")
 	rv = strings.Replace(rv, "ph", "fh", -1)
-	rv = strings.Replace(rv, "b", "p", -1)
+	rv = translate(rv, "b", "p")
 	rv = strings.Replace(rv, "sh", "s2", -1)
-	rv = strings.Replace(rv, "z", "s", -1)
+	rv = translate(rv, "z", "s")
 	
-	
-	re2 = sre2.MustParse("^[aiueo]")
-	
-	if match := re2.Match(rv); match {
+	if len(rv) > 0 && byteIn(rv[0], "aiueo") {
 		rv = "A" + rv[1:]
 	}
 	
-	rv = strings.Replace(rv, "a", "3", -1)
-	rv = strings.Replace(rv, "i", "3", -1)
-	rv = strings.Replace(rv, "u", "3", -1)
-	rv = strings.Replace(rv, "e", "3", -1)
-	rv = strings.Replace(rv, "o", "3", -1)
-	
-	rv = strings.Replace(rv, "j", "y", -1)
+	rv = translate(rv, "aiueoj", "33333y")
 	
-	if rv[:2] == "y3" {
+	if len(rv) >= 2 && rv[:2] == "y3" {
 		rv = "Y3" + rv[2:]
 	}
 	
-	if rv[0] == 'y' {
+	if len(rv) > 0 && rv[0] == 'y' {
 		rv = "A" + rv[1:]
 	}
 	
-	rv = strings.Replace(rv, "y", "3", -1)
+	rv = translate(rv, "y", "3")
 	rv = strings.Replace(rv, "3gh3", "3kh3", -1)
 	rv = strings.Replace(rv, "gh", "22", -1)
-	rv = strings.Replace(rv, "g", "k", -1)
-	rv = strings.Replace(rv, "e", "3", -1)
+	rv = translate(rv, "g", "k")
 	
-	for _, sc := range []string{"s", "t", "p", "k", "f", "m", "n"} {
-		re, _ = regexp.Compile(string(sc) + "+")
-		rv = re.ReplaceAllString(rv, strings.ToUpper(sc))
-	}
+	rv = collapseRuns(rv, "stpkfmn")
 	
 	rv = strings.Replace(rv, "w3", "W3", -1)
 	rv = strings.Replace(rv, "wh3", "Wh3", -1)
 
-	if rv[:len(rv)] == "w" {
-		rv = rv[:len(rv)] + "3"
+	if strings.HasSuffix(rv, "w") {
+		rv = rv[:len(rv)-1] + "3"
 	}
 	
 	rv = strings.Replace(rv, "w", "2", -1)
 	
-	if rv[0] == 'h' {
+	if len(rv) > 0 && rv[0] == 'h' {
 		rv = "A" + rv[1:]
 	}
 	
 	rv = strings.Replace(rv, "h", "2", -1)
 	rv = strings.Replace(rv, "r3", "R3", -1)
 	
-	if rv[:len(rv)] == "r" {
-		rv = rv[:len(rv)] + "3"
+	if strings.HasSuffix(rv, "r") {
+		rv = rv[:len(rv)-1] + "3"
 	}
 	
 	rv = strings.Replace(rv, "r", "2", -1)
 	rv = strings.Replace(rv, "l3", "L3", -1)
 	
-	if rv[:len(rv)] == "l" {
-		rv = rv[:len(rv)] + "3"
+	if strings.HasSuffix(rv, "l") {
+		rv = rv[:len(rv)-1] + "3"
 	}
 	
 	rv = strings.Replace(rv, "l", "2", -1)
-	rv = strings.Replace(rv, "2", "", -1)
 	
-	if rv[len(rv)-1] == '3' {
+	if !opts.KeepMarkers {
+		rv = strings.Replace(rv, "2", "", -1)
+	}
+	
+	if len(rv) > 0 && rv[len(rv)-1] == '3' {
 		rv = rv[:len(rv)-1] + "A"
 	}
 	
+	size := 10
+	if opts.Length > 0 {
+		size = opts.Length
+	}
+	
+	if opts.KeepMarkers {
+		if len(rv) < size {
+			rv = (rv + strings.Repeat("1", size))[0:size]
+		}
+		return rv
+	}
+	
 	rv = strings.Replace(rv, "3", "", -1)
 	
-	rv = rv + "1111111111"
+	rv = rv + strings.Repeat("1", size)
 
-	return rv[0:10]
+	return rv[0:size]
 }
 
 
+/**
+ * Caverphone 2.0 with the code length scaled to the name: ten
+ * characters, or half the number of letters in text (rounded up) if
+ * that is more. Names of up to twenty letters thus code as Caverphone,
+ * while long compound names keep the sounds which the ten characters
+ * would cut off.
+ */
+func CaverphoneAdaptive(text string) string {
+	size := (len(lowerAlpha(text)) + 1) / 2
+	if size < 10 {
+		size = 10
+	}
+	return CaverphoneWithOpts(text, CaverphoneOpts{Length: size})
+}
 
+
+
+/**
+ * This is Caverphone algorithm version 1.0, by David Hood, 2002, as
+ * first used by the Caversham Project: a six character code padded
+ * with ones. Unlike 2.0 it never codes a final vowel, and treats Y and
+ * J as consonants, so "Peter" is PT1111 where Caverphone gives
+ * PTA1111111. Caverphone itself stays at version 2.0.
+ */
+func CaverphoneV1(text string) string {
+	
+	rv := lowerAlpha(text)
+	
+	for _, p := range []string{"cough", "rough", "tough", "enough"} {
+		if strings.HasPrefix(rv, p) {
+			l := len(p) - 2
+			rv = rv[:l] + "2f" + rv[l+2:]
+			break
+		}
+	}
+	
+	if strings.HasPrefix(rv, "gn") {
+		rv = "2n" + rv[2:]
+	}
+	
+	if strings.HasSuffix(rv, "mb") {
+		rv = rv[:len(rv)-2] + "m2"
+	}
+	
+	rv = strings.Replace(rv, "cq", "2q", -1)
+	rv = strings.Replace(rv, "ci", "si", -1)
+	rv = strings.Replace(rv, "ce", "se", -1)
+	rv = strings.Replace(rv, "cy", "sy", -1)
+	rv = strings.Replace(rv, "tch", "2ch", -1)
+	rv = translate(rv, "cqxv", "kkkf")
+	rv = strings.Replace(rv, "dg", "2g", -1)
+	rv = strings.Replace(rv, "tio", "sio", -1)
+	rv = strings.Replace(rv, "tia", "sia", -1)
+	rv = translate(rv, "d", "t")
+	rv = strings.Replace(rv, "ph", "fh", -1)
+	rv = translate(rv, "b", "p")
+	rv = strings.Replace(rv, "sh", "s2", -1)
+	rv = translate(rv, "z", "s")
+	
+	if len(rv) > 0 && byteIn(rv[0], "aeiou") {
+		rv = "A" + rv[1:]
+	}
+	
+	rv = translate(rv, "aeiou", "33333")
+	rv = strings.Replace(rv, "3gh3", "3kh3", -1)
+	rv = strings.Replace(rv, "gh", "22", -1)
+	rv = translate(rv, "g", "k")
+	
+	rv = collapseRuns(rv, "stpkfmn")
+	
+	rv = strings.Replace(rv, "w3", "W3", -1)
+	rv = strings.Replace(rv, "wy", "Wy", -1)
+	rv = strings.Replace(rv, "wh3", "Wh3", -1)
+	rv = strings.Replace(rv, "why", "Why", -1)
+	rv = strings.Replace(rv, "w", "2", -1)
+	
+	if len(rv) > 0 && rv[0] == 'h' {
+		rv = "A" + rv[1:]
+	}
+	
+	rv = strings.Replace(rv, "h", "2", -1)
+	rv = strings.Replace(rv, "r3", "R3", -1)
+	rv = strings.Replace(rv, "ry", "Ry", -1)
+	rv = strings.Replace(rv, "r", "2", -1)
+	rv = strings.Replace(rv, "l3", "L3", -1)
+	rv = strings.Replace(rv, "ly", "Ly", -1)
+	rv = strings.Replace(rv, "l", "2", -1)
+	rv = translate(rv, "j", "y")
+	rv = strings.Replace(rv, "y3", "Y3", -1)
+	rv = strings.Replace(rv, "y", "2", -1)
+	
+	rv = strings.Replace(rv, "2", "", -1)
+	rv = strings.Replace(rv, "3", "", -1)
+	
+	return (rv + strings.Repeat("1", 6))[0:6]
+}
+
+
+
+
+/**
+ * Lower case text, keeping only the letters a to z.
+ */
+func lowerAlpha(text string) string {
+	text = strings.ToLower(text)
+	b := make([]byte, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		if text[i] >= 'a' && text[i] <= 'z' {
+			b = append(b, text[i])
+		}
+	}
+	return string(b)
+}
+
+
+/**
+ * Replace each byte of s found in from with the byte at the same index
+ * in to, in a single pass.
+ */
+func translate(s string, from, to string) string {
+	b := []byte(s)
+	for i, c := range b {
+		for j := 0; j < len(from); j++ {
+			if c == from[j] {
+				b[i] = to[j]
+				break
+			}
+		}
+	}
+	return string(b)
+}
+
+
+/**
+ * Replace each run of any one of letters in s with the single upper
+ * case form of that letter, e.g. "ssat" becomes "Sat".
+ */
+func collapseRuns(s string, letters string) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if byteIn(c, letters) {
+			if i > 0 && s[i-1] == c {
+				continue
+			}
+			c -= 'a' - 'A'
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
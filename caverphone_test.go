@@ -51,3 +51,96 @@ func TestCaverphone(t *testing.T) {
 	checkString(t, Caverphone("Thompson"), "TMPSN11111", "should match")
 	checkString(t, Caverphone("Whitlam"), "WTLM111111", "should match")
 }
+
+func TestCaverphoneMb(t *testing.T) {
+	checkString(t, Caverphone("lamb"), "LM11111111", "final mb should code as m")
+	checkString(t, Caverphone("comb"), "KM11111111", "final mb should code as m")
+	checkString(t, Caverphone("thumb"), "TM11111111", "final mb should code as m")
+	checkString(t, CaverphoneWithOpts("lamb", CaverphoneOpts{KeepMarkers: true}), "L3M2111111", "b should be silent")
+	checkString(t, Caverphone("Lambert"), "LMPT111111", "inner mb should keep the b")
+}
+
+func TestCaverphoneFinal(t *testing.T) {
+	marked := CaverphoneOpts{KeepMarkers: true}
+	checkString(t, Caverphone("Barlow"), "PLA1111111", "final w should code as a vowel")
+	checkString(t, CaverphoneWithOpts("Peter", marked), "P3T3A11111", "final r should code as a vowel")
+	checkString(t, CaverphoneWithOpts("Carl", marked), "K32A111111", "final l should code as a vowel")
+	checkString(t, Caverphone("Carl"), "KA11111111", "final r and l should still code")
+}
+
+func TestCaverphoneKeepMarkers(t *testing.T) {
+	marked := CaverphoneOpts{KeepMarkers: true}
+	checkString(t, CaverphoneWithOpts("Stevenson", marked), "ST3F3NS3N1", "should keep vowel markers")
+	checkString(t, Caverphone("Stevenson"), "STFNSN1111", "default should strip markers")
+	checkString(t, CaverphoneWithOpts("Thompson", marked), "T23MPS3N11", "should keep silent markers")
+	checkString(t, CaverphoneWithOpts("Stevenson", CaverphoneOpts{}), Caverphone("Stevenson"), "zero opts should be standard")
+}
+
+func TestCaverphoneShort(t *testing.T) {
+	checkString(t, Caverphone("A"), "A111111111", "single vowel should code")
+	checkString(t, Caverphone("y"), "A111111111", "single y should code")
+	checkString(t, Caverphone("h"), "A111111111", "single h should code")
+	checkString(t, Caverphone("Ho"), "AA11111111", "h and vowel should code")
+	checkString(t, Caverphone("Mb"), "M111111111", "mb alone should code")
+	checkString(t, Caverphone("12-3"), "1111111111", "no letters should give an empty code")
+	checkString(t, Caverphone("a"), "A111111111", "single lower case vowel should code")
+	checkString(t, Caverphone("E"), "A111111111", "single upper case vowel should code")
+	checkString(t, Caverphone("."), "1111111111", "punctuation alone should give an empty code")
+	checkString(t, Caverphone(""), "1111111111", "blank text should give an empty code")
+	checkString(t, CaverphoneWithOpts("", CaverphoneOpts{Length: 4}), "1111", "blank text should pad to the length")
+	checkString(t, CaverphoneWithOpts("y", CaverphoneOpts{KeepMarkers: true}), "A111111111", "should pad marked codes")
+	for _, name := range []string{"w", "r", "l", "j", "Y3", "gh", "e!"} {
+		checkState(t, len(Caverphone(name)) == 10, "short input should give ten characters: "+name)
+	}
+}
+
+func TestCaverphoneAdaptive(t *testing.T) {
+	checkString(t, CaverphoneAdaptive("Stevenson"), Caverphone("Stevenson"), "short names should code as Caverphone")
+	checkState(t, len(CaverphoneAdaptive("Wolfeschlegelsteinhausen")) == 12, "24 letters should give twelve characters")
+	checkString(t, CaverphoneAdaptive("Wolfeschlegelsteinhausen")[:10], Caverphone("Wolfeschlegelsteinhausen"),
+		"should extend the standard code")
+
+	checkString(t, Caverphone("Wolfeschlegelsteinhausen"), Caverphone("Wolfeschlegelsteinhauser"), "standard codes should saturate")
+	checkState(t, CaverphoneAdaptive("Wolfeschlegelsteinhausen") != CaverphoneAdaptive("Wolfeschlegelsteinhauser"),
+		"adaptive codes should tell long names apart")
+	checkString(t, CaverphoneWithOpts("Lee", CaverphoneOpts{Length: 4}), Caverphone("Lee")[:4], "should cut to length")
+}
+
+var caverphoneBenchNames = []string{
+	"mayer", "meier", "Henrichsen", "Henricsson", "Henriksson", "Hinrichsen",
+	"Stevenson", "Peter", "Karleen", "Thompson", "Whitlam", "Tough",
+	"Gnash", "Catherine", "Knight", "Wright", "Lee", "Yorke",
+}
+
+func BenchmarkCaverphone(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, name := range caverphoneBenchNames {
+			Caverphone(name)
+		}
+	}
+}
+
+type caverphoneV1Test struct {
+	in, out string
+}
+
+var caverphoneV1Tests = []caverphoneV1Test {
+	caverphoneV1Test{"Lee", "L11111"},
+	caverphoneV1Test{"Thompson", "TMPSN1"},
+	caverphoneV1Test{"David", "TFT111"},
+	caverphoneV1Test{"Stevenson", "STFNSN"},
+	caverphoneV1Test{"Peter", "PT1111"},
+	caverphoneV1Test{"Ready", "RT1111"},
+	caverphoneV1Test{"Whittle", "WTL111"},
+	caverphoneV1Test{"Henrichsen", "ANRKSN"},
+	caverphoneV1Test{"Hinrichsen", "ANRKSN"},
+	caverphoneV1Test{"Tough", "TF1111"},
+	caverphoneV1Test{"", "111111"},
+}
+
+func TestCaverphoneV1(t *testing.T) {
+	for _, ct := range caverphoneV1Tests {
+		checkString(t, CaverphoneV1(ct.in), ct.out, "CaverphoneV1 of "+ct.in)
+	}
+	checkString(t, Caverphone("Peter"), "PTA1111111", "Caverphone should stay at 2.0")
+}
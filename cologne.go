@@ -0,0 +1,132 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+)
+
+
+func byteIn(c byte, set string) bool {
+	for i := 0; i < len(set); i++ {
+		if set[i] == c {
+			return true
+		}
+	}
+	return false
+}
+
+
+/**
+ * This is Kölner Phonetik (Cologne phonetics), a Soundex-like code
+ * tuned for German names. Umlauts are read as their vowels and ß as S,
+ * so "Müller-Lüdenscheidt" is 65752682; other letters are skipped.
+ * based on: Hans Joachim Postel, "Die Kölner Phonetik", 1969
+ */
+func ColognePhonetic(word string) string {
+	
+	letters := make([]byte, 0, len(word))
+	
+	for _, c := range strings.ToUpper(word) {
+		switch c {
+		case 'Ä':
+			c = 'A'
+		case 'Ö':
+			c = 'O'
+		case 'Ü':
+			c = 'U'
+		case 'ß':
+			c = 'S'
+		}
+		if c >= 'A' && c <= 'Z' {
+			letters = append(letters, byte(c))
+		}
+	}
+	
+	codes := ""
+	
+	for i, c := range letters {
+		var prev, next byte
+		if i > 0 {
+			prev = letters[i-1]
+		}
+		if i+1 < len(letters) {
+			next = letters[i+1]
+		}
+		
+		switch {
+		case byteIn(c, "AEIJOUY"):
+			codes += "0"
+		case c == 'H':
+			// no code
+		case c == 'B':
+			codes += "1"
+		case c == 'P':
+			if next == 'H' {
+				codes += "3"
+			} else {
+				codes += "1"
+			}
+		case c == 'D' || c == 'T':
+			if byteIn(next, "CSZ") {
+				codes += "8"
+			} else {
+				codes += "2"
+			}
+		case byteIn(c, "FVW"):
+			codes += "3"
+		case byteIn(c, "GKQ"):
+			codes += "4"
+		case c == 'C':
+			if i == 0 {
+				if byteIn(next, "AHKLOQRUX") {
+					codes += "4"
+				} else {
+					codes += "8"
+				}
+			} else if byteIn(next, "AHKOQUX") && !byteIn(prev, "SZ") {
+				codes += "4"
+			} else {
+				codes += "8"
+			}
+		case c == 'X':
+			if byteIn(prev, "CKQ") {
+				codes += "8"
+			} else {
+				codes += "48"
+			}
+		case c == 'L':
+			codes += "5"
+		case c == 'M' || c == 'N':
+			codes += "6"
+		case c == 'R':
+			codes += "7"
+		case c == 'S' || c == 'Z':
+			codes += "8"
+		}
+	}
+	
+	// collapse repeated codes, then drop every zero but a leading one
+	rv := ""
+	
+	for i := 0; i < len(codes); i++ {
+		if i > 0 && codes[i] == codes[i-1] {
+			continue
+		}
+		if codes[i] == '0' && len(rv) > 0 {
+			continue
+		}
+		rv += string(codes[i])
+	}
+	
+	return rv
+}
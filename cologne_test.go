@@ -0,0 +1,40 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+type cologneTest struct {
+	in, out string
+}
+
+var cologneTests = []cologneTest {
+	cologneTest{"Wikipedia", "3412"},
+	cologneTest{"Breschnew", "17863"},
+	cologneTest{"Schmidt", "862"},
+	cologneTest{"Meyer", "67"},
+	cologneTest{"Müller-Lüdenscheidt", "65752682"},
+	cologneTest{"MÜLLER", "657"},
+	cologneTest{"Strauß", "8278"},
+	cologneTest{"Häcker", "047"},
+}
+
+func TestColognePhonetic(t *testing.T) {
+	for _, dt := range cologneTests {
+		rv := ColognePhonetic(dt.in)
+		if rv != dt.out {
+			t.Errorf("ColognePhonetic(%s) = `%s`, want `%s`", dt.in, rv, dt.out)
+		}
+	}
+}
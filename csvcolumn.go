@@ -0,0 +1,90 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"csv"
+	"fmt"
+	"io"
+	"os"
+)
+
+
+/**
+ * Read CSV from r and write it to w with one extra column holding the
+ * code of column col (counting from zero) under enc. Every row,
+ * including any header, is encoded.
+ */
+func EncodeCSVColumn(r io.Reader, w io.Writer, col int, enc Encoder) os.Error {
+	return encodeCSV(r, w, col, enc, false, "")
+}
+
+
+/**
+ * Like EncodeCSVColumn, but the first row is a header: it is copied
+ * with name added as the title of the new column.
+ */
+func EncodeCSVColumnHeader(r io.Reader, w io.Writer, col int, enc Encoder, name string) os.Error {
+	return encodeCSV(r, w, col, enc, true, name)
+}
+
+
+// An io.Writer that remembers the first error of the one it wraps, as
+// csv.Writer drops the errors of its Flush.
+type errorWriter struct {
+	w   io.Writer
+	err os.Error
+}
+
+
+func (e *errorWriter) Write(p []byte) (int, os.Error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
+
+
+func encodeCSV(r io.Reader, w io.Writer, col int, enc Encoder, header bool, name string) os.Error {
+	
+	reader := csv.NewReader(r)
+	out := &errorWriter{w: w}
+	writer := csv.NewWriter(out)
+	
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if err == os.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if col < 0 || col >= len(record) {
+			return fmt.Errorf("row %d has no column %d", row, col)
+		}
+		
+		if header && row == 0 {
+			record = append(record, name)
+		} else {
+			record = append(record, enc.Encode(record[col]))
+		}
+		
+		if err = writer.Write(record); err != nil {
+			return err
+		}
+	}
+	
+	writer.Flush()
+	return out.err
+}
@@ -0,0 +1,55 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+
+// A writer that fails every write.
+type failingWriter struct{}
+
+
+func (failingWriter) Write(p []byte) (int, os.Error) {
+	return 0, fmt.Errorf("disk full")
+}
+
+
+func TestEncodeCSVColumn(t *testing.T) {
+	in := "1,Robert\n2,\"Rupert, Jr\"\n"
+	var out bytes.Buffer
+	err := EncodeCSVColumn(strings.NewReader(in), &out, 1, SoundexEncoder)
+	checkState(t, err == nil, "should encode without error")
+	checkString(t, out.String(), "1,Robert,R163\n2,\"Rupert, Jr\",R163\n", "should append codes")
+
+	in = "id,name\n1,Robin\n"
+	out.Reset()
+	err = EncodeCSVColumnHeader(strings.NewReader(in), &out, 1, SoundexEncoder, "soundex")
+	checkState(t, err == nil, "should encode without error")
+	checkString(t, out.String(), "id,name,soundex\n1,Robin,R150\n", "should copy the header")
+
+	out.Reset()
+	err = EncodeCSVColumn(strings.NewReader(in), &out, 5, SoundexEncoder)
+	checkState(t, err != nil, "should fail on a missing column")
+}
+
+
+func TestEncodeCSVColumnWriteError(t *testing.T) {
+	in := "1,Robert\n"
+	err := EncodeCSVColumn(strings.NewReader(in), failingWriter{}, 1, SoundexEncoder)
+	checkState(t, err != nil, "should report a failed flush")
+}
@@ -0,0 +1,194 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"sort"
+	"strings"
+)
+
+
+const daitchMokotoffLen = 6
+
+
+// A letter or cluster coded by DaitchMokotoff, with its digits at the
+// start of a name, before a vowel, and anywhere else. Alternative
+// readings are split by "|"; a blank code adds no digit.
+type dmRule struct {
+	pattern, start, vowel, other string
+}
+
+
+/**
+ * The Daitch-Mokotoff coding chart. Clusters come before any shorter
+ * cluster they begin with, as the first matching rule is used.
+ */
+var dmRules = []dmRule{
+	{"AI", "0", "1", ""}, {"AJ", "0", "1", ""}, {"AY", "0", "1", ""}, {"AU", "0", "7", ""},
+	{"A", "0", "", ""},
+	{"B", "7", "7", "7"},
+	{"CHS", "5", "54", "54"}, {"CH", "5|4", "5|4", "5|4"}, {"CK", "5|45", "5|45", "5|45"},
+	{"CSZ", "4", "4", "4"}, {"CZS", "4", "4", "4"}, {"CZ", "4", "4", "4"}, {"CS", "4", "4", "4"},
+	{"C", "5|4", "5|4", "5|4"},
+	{"DRZ", "4", "4", "4"}, {"DRS", "4", "4", "4"}, {"DSH", "4", "4", "4"}, {"DSZ", "4", "4", "4"},
+	{"DZH", "4", "4", "4"}, {"DZS", "4", "4", "4"}, {"DS", "4", "4", "4"}, {"DZ", "4", "4", "4"},
+	{"DT", "3", "3", "3"}, {"D", "3", "3", "3"},
+	{"EI", "0", "1", ""}, {"EJ", "0", "1", ""}, {"EY", "0", "1", ""}, {"EU", "1", "1", ""},
+	{"E", "0", "", ""},
+	{"FB", "7", "7", "7"}, {"F", "7", "7", "7"},
+	{"G", "5", "5", "5"},
+	{"H", "5", "5", ""},
+	{"IA", "1", "", ""}, {"IE", "1", "", ""}, {"IO", "1", "", ""}, {"IU", "1", "", ""},
+	{"I", "0", "", ""},
+	{"J", "1|4", "|4", "|4"},
+	{"KS", "5", "54", "54"}, {"KH", "5", "5", "5"}, {"K", "5", "5", "5"},
+	{"L", "8", "8", "8"},
+	{"MN", "66", "66", "66"}, {"M", "6", "6", "6"},
+	{"NM", "66", "66", "66"}, {"N", "6", "6", "6"},
+	{"OI", "0", "1", ""}, {"OJ", "0", "1", ""}, {"OY", "0", "1", ""},
+	{"O", "0", "", ""},
+	{"PF", "7", "7", "7"}, {"PH", "7", "7", "7"}, {"P", "7", "7", "7"},
+	{"Q", "5", "5", "5"},
+	{"RTZ", "94|4", "94|4", "94|4"}, {"RZ", "94|4", "94|4", "94|4"}, {"RS", "94|4", "94|4", "94|4"},
+	{"R", "9", "9", "9"},
+	{"SCHTSCH", "2", "4", "4"}, {"SCHTSH", "2", "4", "4"}, {"SCHTCH", "2", "4", "4"},
+	{"SCHT", "2", "43", "43"}, {"SCHD", "2", "43", "43"}, {"SCH", "4", "4", "4"},
+	{"SHTCH", "2", "4", "4"}, {"SHTSH", "2", "4", "4"}, {"SHCH", "2", "4", "4"},
+	{"SHT", "2", "43", "43"}, {"SHD", "2", "43", "43"}, {"SH", "4", "4", "4"},
+	{"STSCH", "2", "4", "4"}, {"STCH", "2", "4", "4"}, {"STRZ", "2", "4", "4"},
+	{"STRS", "2", "4", "4"}, {"STSH", "2", "4", "4"}, {"ST", "2", "43", "43"},
+	{"SZCZ", "2", "4", "4"}, {"SZCS", "2", "4", "4"}, {"SZT", "2", "43", "43"},
+	{"SZD", "2", "43", "43"}, {"SZ", "4", "4", "4"},
+	{"SC", "2", "4", "4"}, {"SD", "2", "43", "43"}, {"S", "4", "4", "4"},
+	{"TTSCH", "4", "4", "4"}, {"TTCH", "4", "4", "4"}, {"TTSZ", "4", "4", "4"},
+	{"TTS", "4", "4", "4"}, {"TTZ", "4", "4", "4"},
+	{"TSCH", "4", "4", "4"}, {"TCH", "4", "4", "4"}, {"TRZ", "4", "4", "4"}, {"TRS", "4", "4", "4"},
+	{"TSH", "4", "4", "4"}, {"TSZ", "4", "4", "4"}, {"TZS", "4", "4", "4"},
+	{"TH", "3", "3", "3"}, {"TS", "4", "4", "4"}, {"TC", "4", "4", "4"}, {"TZ", "4", "4", "4"},
+	{"T", "3", "3", "3"},
+	{"UI", "0", "1", ""}, {"UJ", "0", "1", ""}, {"UY", "0", "1", ""}, {"UE", "0", "", ""},
+	{"U", "0", "", ""},
+	{"V", "7", "7", "7"},
+	{"W", "7", "7", "7"},
+	{"X", "5", "54", "54"},
+	{"Y", "1", "", ""},
+	{"ZHDZH", "2", "4", "4"}, {"ZDZH", "2", "4", "4"}, {"ZDZ", "2", "4", "4"},
+	{"ZSCH", "4", "4", "4"}, {"ZHD", "2", "43", "43"}, {"ZSH", "4", "4", "4"},
+	{"ZD", "2", "43", "43"}, {"ZH", "4", "4", "4"}, {"ZS", "4", "4", "4"},
+	{"Z", "4", "4", "4"},
+}
+
+
+// One reading of a name being coded: its digits so far, and the code
+// of the last letter or cluster, which the next may not repeat.
+type dmBranch struct {
+	code, last string
+}
+
+
+/**
+ * Daitch-Mokotoff Soundex, by Randy Daitch and Gary Mokotoff, 1985,
+ * for Slavic, Germanic and Yiddish surnames: six digit codes, sorted.
+ * A cluster such as CH or RS with two readings gives a code for each,
+ * so "Peters" is both 734000 and 739400. Clusters are coded by where
+ * they fall: at the start, before a vowel, or elsewhere. Accents are
+ * stripped first. Returns nil for a name without letters.
+ */
+func DaitchMokotoff(name string) []string {
+	
+	value := []int(strings.ToUpper(name))
+	for i, c := range value {
+		for j, a := range accented {
+			if c == a {
+				value[i] = unaccented[j]
+			}
+		}
+	}
+	w := strings.ToUpper(lowerAlpha(string(value)))
+	if len(w) == 0 {
+		return nil
+	}
+	
+	branches := []dmBranch{{}}
+	var prev byte
+	
+	for i := 0; i < len(w); {
+		rule := dmRules[0]
+		for _, r := range dmRules {
+			if strings.HasPrefix(w[i:], r.pattern) {
+				rule = r
+				break
+			}
+		}
+		
+		codes := rule.other
+		next := i + len(rule.pattern)
+		if i == 0 {
+			codes = rule.start
+		} else if next < len(w) && byteIn(w[next], "AEIOU") {
+			codes = rule.vowel
+		}
+		
+		// An M and N sounded apart are both coded, though of one digit.
+		force := (prev == 'M' && w[i] == 'N') || (prev == 'N' && w[i] == 'M')
+		
+		forked := make([]dmBranch, 0, len(branches))
+		for _, b := range branches {
+			for _, digits := range strings.Split(codes, "|") {
+				nb := b.add(digits, force)
+				if !dmHasBranch(forked, nb) {
+					forked = append(forked, nb)
+				}
+			}
+		}
+		branches = forked
+		
+		prev = w[i]
+		i = next
+	}
+	
+	seen := make(map[string]bool)
+	rv := make([]string, 0, len(branches))
+	for _, b := range branches {
+		code := padSoundex(b.code, daitchMokotoffLen)
+		if !seen[code] {
+			seen[code] = true
+			rv = append(rv, code)
+		}
+	}
+	sort.Strings(rv)
+	
+	return rv
+}
+
+
+// The branch b followed by digits, unless they repeat its last code.
+func (b dmBranch) add(digits string, force bool) dmBranch {
+	if force || !strings.HasSuffix(b.last, digits) {
+		b.code += digits
+		if len(b.code) > daitchMokotoffLen {
+			b.code = b.code[:daitchMokotoffLen]
+		}
+	}
+	b.last = digits
+	return b
+}
+
+
+func dmHasBranch(branches []dmBranch, b dmBranch) bool {
+	for _, o := range branches {
+		if o == b {
+			return true
+		}
+	}
+	return false
+}
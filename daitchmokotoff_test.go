@@ -0,0 +1,51 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+	"testing"
+)
+
+
+type daitchMokotoffTest struct {
+	name, codes string
+}
+
+
+var daitchMokotoffTests = []daitchMokotoffTest {
+	daitchMokotoffTest{"Peters", "734000 739400"},
+	daitchMokotoffTest{"Moskowitz", "645740"},
+	daitchMokotoffTest{"Moskovitz", "645740"},
+	daitchMokotoffTest{"Auerbach", "097500 097400"},
+	daitchMokotoffTest{"Schwarz", "474000 479400"},
+	daitchMokotoffTest{"Jackson", "154600 454600 145460 445460"},
+	daitchMokotoffTest{"Müller", "689000"},
+	daitchMokotoffTest{"Ohrbach", "097500 097400"},
+	daitchMokotoffTest{"", ""},
+}
+
+
+func TestDaitchMokotoff(t *testing.T) {
+	for _, tt := range daitchMokotoffTests {
+		codes := DaitchMokotoff(tt.name)
+		want := strings.Fields(tt.codes)
+		checkState(t, len(codes) == len(want), "wrong number of codes for " + tt.name + ": " + strings.Join(codes, " "))
+		for _, w := range want {
+			found := false
+			for _, c := range codes {
+				found = found || c == w
+			}
+			checkState(t, found, tt.name + " should code as " + w)
+		}
+	}
+}
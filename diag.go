@@ -0,0 +1,71 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"unicode"
+	"utf8"
+)
+
+
+/**
+ * Encode name with enc, also reporting whether the code is stable: it
+ * is not if doubling any letter, or dropping any letter but the first,
+ * gives a different code. An unstable code makes for a fragile match.
+ */
+func EncodeStable(name string, enc Encoder) (code string, stable bool) {
+	
+	code = enc.Encode(name)
+	first := true
+	
+	for i, c := range name {
+		if !unicode.IsLetter(c) {
+			continue
+		}
+		j := i + utf8.RuneLen(c)
+		if enc.Encode(name[:j]+name[i:]) != code {
+			return code, false
+		}
+		if !first && enc.Encode(name[:i]+name[j:]) != code {
+			return code, false
+		}
+		first = false
+	}
+	
+	return code, true
+}
+
+
+/**
+ * Find where the codes of a and b under enc first diverge, returning the
+ * rune position and the runes of each code there. Where one code ends
+ * first, its rune is -1. Equal codes give a pos of -1.
+ */
+func FirstCodeDifference(a, b string, enc Encoder) (pos int, ca, cb int) {
+	
+	ra, rb := []int(enc.Encode(a)), []int(enc.Encode(b))
+	
+	for pos = 0; pos < len(ra) || pos < len(rb); pos++ {
+		ca, cb = -1, -1
+		if pos < len(ra) {
+			ca = ra[pos]
+		}
+		if pos < len(rb) {
+			cb = rb[pos]
+		}
+		if ca != cb {
+			return pos, ca, cb
+		}
+	}
+	
+	return -1, -1, -1
+}
@@ -0,0 +1,41 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestEncodeStable(t *testing.T) {
+	code, stable := EncodeStable("Lee", SoundexEncoder)
+	checkString(t, code, "L000", "should return the code")
+	checkState(t, stable, "Lee should be stable under soundex")
+
+	code, stable = EncodeStable("Robert", SoundexEncoder)
+	checkString(t, code, "R163", "should return the code")
+	checkState(t, !stable, "dropping the b of Robert changes its code")
+}
+
+func TestFirstCodeDifference(t *testing.T) {
+	pos, ca, cb := FirstCodeDifference("Robert", "Rupert", SoundexEncoder)
+	checkState(t, pos == -1 && ca == -1 && cb == -1, "equal codes should have no difference")
+
+	pos, ca, cb = FirstCodeDifference("Robert", "Robin", SoundexEncoder)
+	checkState(t, pos == 2 && ca == '6' && cb == '5', "R163 and R150 should differ at 2")
+
+	identity := NewEncoder("identity", func(text string) string {
+		return text
+	})
+	pos, ca, cb = FirstCodeDifference("Lee", "Leeds", identity)
+	checkState(t, pos == 3 && ca == -1 && cb == 'd', "shorter code should give -1 where it ends")
+}
@@ -0,0 +1,223 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"sort"
+)
+
+
+/**
+ * Cost of substituting one Soundex digit for another, keyed by the two
+ * digits in ascending order. Pairs not listed cost 1, as does
+ * inserting or deleting a digit.
+ */
+var DigitSubstitutionCost = map[string]float64{
+	"15": 0.5,  // b, p vs m: labials
+	"35": 0.5,  // d, t vs n: alveolars
+	"46": 0.5,  // l vs r: liquids
+	"12": 0.75, // f, v vs s, z: fricatives
+	"23": 0.75, // k, g vs d, t: plosives
+}
+
+
+func digitCost(a, b byte) float64 {
+	if a == b {
+		return 0
+	}
+	if a > b {
+		a, b = b, a
+	}
+	if cost, ok := DigitSubstitutionCost[string([]byte{a, b})]; ok {
+		return cost
+	}
+	return 1
+}
+
+
+func min3(a, b, c float64) float64 {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+
+/**
+ * Levenshtein distance between a and b, where substituting one byte
+ * for another costs cost(x, y) and inserting or deleting costs 1.
+ */
+func editDistance(a, b string, cost func(x, y byte) float64) float64 {
+	
+	prev := make([]float64, len(b)+1)
+	curr := make([]float64, len(b)+1)
+	
+	for j := range prev {
+		prev[j] = float64(j)
+	}
+	
+	for i := 1; i <= len(a); i++ {
+		curr[0] = float64(i)
+		for j := 1; j <= len(b); j++ {
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost(a[i-1], b[j-1]))
+		}
+		prev, curr = curr, prev
+	}
+	
+	return prev[len(b)]
+}
+
+
+/**
+ * Edit distance between the codes of a and b under enc, where close
+ * Soundex digits (see DigitSubstitutionCost) are cheaper to substitute.
+ * Near-miss codes thus rank closer than plain Levenshtein would put them.
+ */
+func WeightedCodeDistance(a, b string, enc Encoder) float64 {
+	return editDistance(enc.Encode(a), enc.Encode(b), digitCost)
+}
+
+
+func unitCost(x, y byte) float64 {
+	if x == y {
+		return 0
+	}
+	return 1
+}
+
+
+type neighbor struct {
+	name string
+	dist float64
+	pos  int // position in the corpus, to break ties
+}
+
+type neighbors []neighbor
+
+func (n neighbors) Len() int      { return len(n) }
+func (n neighbors) Swap(i, j int) { n[i], n[j] = n[j], n[i] }
+func (n neighbors) Less(i, j int) bool {
+	if n[i].dist != n[j].dist {
+		return n[i].dist < n[j].dist
+	}
+	return n[i].pos < n[j].pos
+}
+
+
+/**
+ * Score every entry of corpus by the edit distance of its code to code.
+ * This is a brute force scan; an index such as a BK-tree could replace
+ * it by only scoring entries within reach.
+ */
+func scoreCorpus(code string, corpus []string, enc Encoder) neighbors {
+	rv := make(neighbors, len(corpus))
+	for i, name := range corpus {
+		rv[i] = neighbor{name, editDistance(code, enc.Encode(name), unitCost), i}
+	}
+	return rv
+}
+
+
+/**
+ * The k entries of corpus whose codes under enc are nearest to the code
+ * of query by edit distance, nearest first. Ties keep corpus order.
+ */
+func NearestNeighbors(query string, corpus []string, enc Encoder, k int) []string {
+	
+	scored := scoreCorpus(enc.Encode(query), corpus, enc)
+	sort.Sort(scored)
+	
+	if k > len(scored) {
+		k = len(scored)
+	}
+	if k < 0 {
+		k = 0
+	}
+	
+	rv := make([]string, k)
+	for i := 0; i < k; i++ {
+		rv[i] = scored[i].name
+	}
+	return rv
+}
+
+
+/**
+ * Jaro-Winkler similarity of a and b, from 0 (nothing in common) to 1
+ * (identical), comparing runes as given. Runes count as shared if equal
+ * and no further apart than half the longer length; a common prefix of
+ * up to four runes raises the score, as in the classic formulation.
+ */
+func JaroWinkler(a, b string) float64 {
+	
+	ra, rb := []int(a), []int(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	
+	window := len(ra)
+	if len(rb) > window {
+		window = len(rb)
+	}
+	window = window/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	
+	// find the shared runes
+	usedA := make([]bool, len(ra))
+	usedB := make([]bool, len(rb))
+	shared := 0
+	for i, c := range ra {
+		for j := i - window; j <= i+window; j++ {
+			if j >= 0 && j < len(rb) && !usedB[j] && rb[j] == c {
+				usedA[i], usedB[j] = true, true
+				shared++
+				break
+			}
+		}
+	}
+	if shared == 0 {
+		return 0
+	}
+	
+	// count shared runes out of order, in pairs
+	transposed, j := 0, 0
+	for i, c := range ra {
+		if !usedA[i] {
+			continue
+		}
+		for !usedB[j] {
+			j++
+		}
+		if rb[j] != c {
+			transposed++
+		}
+		j++
+	}
+	
+	m := float64(shared)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transposed/2))/m) / 3
+	
+	prefix := 0
+	for prefix < 4 && prefix < len(ra) && prefix < len(rb) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
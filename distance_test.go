@@ -0,0 +1,53 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestWeightedCodeDistance(t *testing.T) {
+	checkState(t, WeightedCodeDistance("Robert", "Rupert", SoundexEncoder) == 0, "same code should be zero")
+
+	// B630 vs B430 (r vs l) and B230 (r vs s) are each one substitution apart.
+	bald := WeightedCodeDistance("Bart", "Bald", SoundexEncoder)
+	bast := WeightedCodeDistance("Bart", "Bast", SoundexEncoder)
+	checkState(t, bald == 0.5, "r vs l should be a cheap substitution")
+	checkState(t, bast == 1, "r vs s should cost a full substitution")
+	checkState(t, bald < bast, "Bald should rank closer to Bart than Bast")
+
+	checkState(t, WeightedCodeDistance("Lee", "Bart", SoundexEncoder) == 3, "L000 vs B630 should differ in three places")
+}
+
+func TestNearestNeighbors(t *testing.T) {
+	corpus := []string{"Smith", "Robin", "Rupert", "Rob", "Roberts"}
+	nearest := NearestNeighbors("Robert", corpus, SoundexEncoder, 3)
+	checkCapture(t, []string{"Rupert", "Roberts", "Robin"}, nearest, "should rank by code distance")
+	nearest = NearestNeighbors("Robert", corpus, SoundexEncoder, 10)
+	checkCapture(t, []string{"Rupert", "Roberts", "Robin", "Rob", "Smith"}, nearest, "should cap k at the corpus size")
+	checkCapture(t, []string{}, NearestNeighbors("Robert", corpus, SoundexEncoder, 0), "should return nothing for k=0")
+}
+
+
+func TestJaroWinkler(t *testing.T) {
+	near := func(got, want float64) bool {
+		return got > want-0.001 && got < want+0.001
+	}
+	checkState(t, near(JaroWinkler("MARTHA", "MARHTA"), 0.961), "transposition should score 0.961")
+	checkState(t, near(JaroWinkler("DWAYNE", "DUANE"), 0.84), "DWAYNE vs DUANE should score 0.84")
+	checkState(t, near(JaroWinkler("DIXON", "DICKSONX"), 0.813), "DIXON vs DICKSONX should score 0.813")
+	checkState(t, JaroWinkler("Robert", "Robert") == 1, "identical names should score 1")
+	checkState(t, JaroWinkler("", "") == 1, "empty names should be identical")
+	checkState(t, JaroWinkler("abc", "xyz") == 0, "disjoint names should score 0")
+	checkState(t, JaroWinkler("abc", "") == 0, "empty name should score 0")
+}
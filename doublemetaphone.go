@@ -0,0 +1,575 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+)
+
+
+// Length of each key given by DoubleMetaphone.
+const doubleMetaphoneLen = 4
+
+
+// Accented capitals folded by DoubleMetaphone, and what each becomes.
+// Ç and Ñ are coded by the algorithm itself, so are kept.
+var (
+	accented   = []int("ÀÁÂÃÄÅÆÈÉÊËÌÍÎÏÐÒÓÔÕÖØÙÚÛÜÝŸß")
+	unaccented = []int("AAAAAAAEEEEIIIIDOOOOOOUUUUYYS")
+)
+
+
+// The upper cased word being coded, and the keys built so far.
+type doubleMetaphone struct {
+	value              []int
+	primary, alternate string
+	slavoGermanic      bool
+}
+
+
+func (m *doubleMetaphone) at(i int) int {
+	if i < 0 || i >= len(m.value) {
+		return 0
+	}
+	return m.value[i]
+}
+
+
+// Whether the runes from start, of the length of the first option, are
+// any of options.
+func (m *doubleMetaphone) is(start int, options ...string) bool {
+	length := len([]int(options[0]))
+	if start < 0 || start+length > len(m.value) {
+		return false
+	}
+	s := string(m.value[start : start+length])
+	for _, o := range options {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+
+func (m *doubleMetaphone) vowel(i int) bool {
+	c := m.at(i)
+	return c != 0 && c < 128 && byteIn(byte(c), "AEIOUY")
+}
+
+
+func (m *doubleMetaphone) add(primary, alternate string) {
+	m.primary += primary
+	m.alternate += alternate
+}
+
+
+func (m *doubleMetaphone) add1(code string) {
+	m.add(code, code)
+}
+
+
+func (m *doubleMetaphone) done() bool {
+	return len(m.primary) >= doubleMetaphoneLen && len(m.alternate) >= doubleMetaphoneLen
+}
+
+
+// Steps past the current rune, or past a doubled one.
+func (m *doubleMetaphone) skip(i int, twice ...string) int {
+	if m.is(i+1, twice...) {
+		return i + 2
+	}
+	return i + 1
+}
+
+
+/**
+ * Double Metaphone, by Lawrence Philips, 2000: a primary key for the
+ * usual English reading of word, and an alternate key for another
+ * likely reading, e.g. of a Slavic, Germanic or Spanish name, each of
+ * up to four characters. Where no cluster is ambiguous the two are
+ * equal. Accents are stripped first, so Müller codes as Muller. "0"
+ * stands for the "th" sound and "X" for "sh", as in Metaphone.
+ */
+func DoubleMetaphone(word string) (primary string, alternate string) {
+	
+	value := []int(strings.ToUpper(strings.TrimSpace(word)))
+	for i, c := range value {
+		for j, a := range accented {
+			if c == a {
+				value[i] = unaccented[j]
+			}
+		}
+	}
+	
+	m := &doubleMetaphone{value: value}
+	s := string(value)
+	m.slavoGermanic = strings.Index(s, "W") >= 0 || strings.Index(s, "K") >= 0 ||
+		strings.Index(s, "CZ") >= 0 || strings.Index(s, "WITZ") >= 0
+	
+	i := 0
+	if m.is(0, "GN", "KN", "PN", "WR", "PS") {
+		i = 1
+	}
+	
+	for i < len(m.value) && !m.done() {
+		switch m.at(i) {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if i == 0 {
+				m.add1("A")
+			}
+			i++
+		case 'B':
+			m.add1("P")
+			i = m.skip(i, "B")
+		case 'Ç':
+			m.add1("S")
+			i++
+		case 'C':
+			i = m.c(i)
+		case 'D':
+			i = m.d(i)
+		case 'F':
+			m.add1("F")
+			i = m.skip(i, "F")
+		case 'G':
+			i = m.g(i)
+		case 'H':
+			if (i == 0 || m.vowel(i-1)) && m.vowel(i+1) {
+				m.add1("H")
+				i += 2
+			} else {
+				i++
+			}
+		case 'J':
+			i = m.j(i)
+		case 'K':
+			m.add1("K")
+			i = m.skip(i, "K")
+		case 'L':
+			i = m.l(i)
+		case 'M':
+			m.add1("M")
+			if m.at(i+1) == 'M' || m.is(i-1, "UMB") && (i+1 == len(m.value)-1 || m.is(i+2, "ER")) {
+				i += 2
+			} else {
+				i++
+			}
+		case 'N':
+			m.add1("N")
+			i = m.skip(i, "N")
+		case 'Ñ':
+			m.add1("N")
+			i++
+		case 'P':
+			if m.at(i+1) == 'H' {
+				m.add1("F")
+				i += 2
+			} else {
+				m.add1("P")
+				i = m.skip(i, "P", "B")
+			}
+		case 'Q':
+			m.add1("K")
+			i = m.skip(i, "Q")
+		case 'R':
+			if i == len(m.value)-1 && !m.slavoGermanic && m.is(i-2, "IE") && !m.is(i-4, "ME", "MA") {
+				m.add("", "R")
+			} else {
+				m.add1("R")
+			}
+			i = m.skip(i, "R")
+		case 'S':
+			i = m.s(i)
+		case 'T':
+			i = m.t(i)
+		case 'V':
+			m.add1("F")
+			i = m.skip(i, "V")
+		case 'W':
+			i = m.w(i)
+		case 'X':
+			if i == 0 {
+				m.add1("S")
+				i++
+			} else {
+				if !(i == len(m.value)-1 && (m.is(i-3, "IAU", "EAU") || m.is(i-2, "AU", "OU"))) {
+					m.add1("KS")
+				}
+				i = m.skip(i, "C", "X")
+			}
+		case 'Z':
+			i = m.z(i)
+		default:
+			i++
+		}
+	}
+	
+	return doubleMetaphoneKey(m.primary), doubleMetaphoneKey(m.alternate)
+}
+
+
+func doubleMetaphoneKey(code string) string {
+	if len(code) > doubleMetaphoneLen {
+		return code[:doubleMetaphoneLen]
+	}
+	return code
+}
+
+
+func (m *doubleMetaphone) c(i int) int {
+	
+	switch {
+	case m.is(i, "CHIA") || i > 1 && !m.vowel(i-2) && m.is(i-1, "ACH") &&
+		(m.at(i+2) != 'I' && m.at(i+2) != 'E' || m.is(i-2, "BACHER", "MACHER")):
+		// Germanic "ach", as in "Bacher"
+		m.add1("K")
+		return i + 2
+	case i == 0 && m.is(i, "CAESAR"):
+		m.add1("S")
+		return i + 2
+	case m.is(i, "CH"):
+		return m.ch(i)
+	case m.is(i, "CZ") && !m.is(i-2, "WICZ"):
+		m.add("S", "X")
+		return i + 2
+	case m.is(i+1, "CIA"):
+		m.add1("X")
+		return i + 3
+	case m.is(i, "CC") && !(i == 1 && m.at(0) == 'M'):
+		if m.is(i+2, "I", "E", "H") && !m.is(i+2, "HU") {
+			// "accident", "succeed", but "bacchus"
+			if i == 1 && m.at(0) == 'A' || m.is(i-1, "UCCEE", "UCCES") {
+				m.add1("KS")
+			} else {
+				m.add1("X")
+			}
+			return i + 3
+		}
+		m.add1("K")
+		return i + 2
+	case m.is(i, "CK", "CG", "CQ"):
+		m.add1("K")
+		return i + 2
+	case m.is(i, "CI", "CE", "CY"):
+		if m.is(i, "CIO", "CIE", "CIA") {
+			m.add("S", "X")
+		} else {
+			m.add1("S")
+		}
+		return i + 2
+	}
+	
+	m.add1("K")
+	switch {
+	case m.is(i+1, " C", " Q", " G"):
+		return i + 3
+	case m.is(i+1, "C", "K", "Q") && !m.is(i+1, "CE", "CI"):
+		return i + 2
+	}
+	return i + 1
+}
+
+
+func (m *doubleMetaphone) ch(i int) int {
+	
+	switch {
+	case i > 0 && m.is(i, "CHAE"):
+		m.add("K", "X")
+	case i == 0 && (m.is(i+1, "HARAC", "HARIS") || m.is(i+1, "HOR", "HYM", "HIA", "HEM")) && !m.is(0, "CHORE"):
+		// Greek roots, as in "chorus"
+		m.add1("K")
+	case m.is(0, "VAN ", "VON ") || m.is(0, "SCH") || m.is(i-2, "ORCHES", "ARCHIT", "ORCHID") ||
+		m.is(i+2, "T", "S") || (m.is(i-1, "A", "O", "U", "E") || i == 0) &&
+		(m.is(i+2, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || i+1 == len(m.value)-1):
+		// Germanic, as in "Schmidt", or before a consonant
+		m.add1("K")
+	case i > 0 && m.is(0, "MC"):
+		m.add1("K")
+	case i > 0:
+		m.add("X", "K")
+	default:
+		m.add1("X")
+	}
+	
+	return i + 2
+}
+
+
+func (m *doubleMetaphone) d(i int) int {
+	
+	switch {
+	case m.is(i, "DG") && m.is(i+2, "I", "E", "Y"):
+		// "edge"
+		m.add1("J")
+		return i + 3
+	case m.is(i, "DG"):
+		// "edgar"
+		m.add1("TK")
+		return i + 2
+	case m.is(i, "DT", "DD"):
+		m.add1("T")
+		return i + 2
+	}
+	
+	m.add1("T")
+	return i + 1
+}
+
+
+func (m *doubleMetaphone) g(i int) int {
+	
+	switch {
+	case m.at(i+1) == 'H':
+		return m.gh(i)
+	case m.at(i+1) == 'N':
+		switch {
+		case i == 1 && m.vowel(0) && !m.slavoGermanic:
+			m.add("KN", "N")
+		case !m.is(i+2, "EY") && m.at(i+1) != 'Y' && !m.slavoGermanic:
+			m.add("N", "KN")
+		default:
+			m.add1("KN")
+		}
+		return i + 2
+	case m.is(i+1, "LI") && !m.slavoGermanic:
+		// "tagliaro"
+		m.add("KL", "L")
+		return i + 2
+	case i == 0 && (m.at(i+1) == 'Y' || m.is(i+1, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.add("K", "J")
+		return i + 2
+	case (m.is(i+1, "ER") || m.at(i+1) == 'Y') && !m.is(0, "DANGER", "RANGER", "MANGER") &&
+		!m.is(i-1, "E", "I") && !m.is(i-1, "RGY", "OGY"):
+		m.add("K", "J")
+		return i + 2
+	case m.is(i+1, "E", "I", "Y") || m.is(i-1, "AGGI", "OGGI"):
+		switch {
+		case m.is(0, "VAN ", "VON ") || m.is(0, "SCH") || m.is(i+1, "ET"):
+			// Germanic
+			m.add1("K")
+		case m.is(i+1, "IER"):
+			m.add1("J")
+		default:
+			m.add("J", "K")
+		}
+		return i + 2
+	}
+	
+	m.add1("K")
+	return m.skip(i, "G")
+}
+
+
+func (m *doubleMetaphone) gh(i int) int {
+	
+	switch {
+	case i > 0 && !m.vowel(i-1):
+		m.add1("K")
+	case i == 0:
+		// "ghislane", "ghiradelli"
+		if m.at(i+2) == 'I' {
+			m.add1("J")
+		} else {
+			m.add1("K")
+		}
+	case i > 1 && m.is(i-2, "B", "H", "D") || i > 2 && m.is(i-3, "B", "H", "D") || i > 3 && m.is(i-4, "B", "H"):
+		// silent, as in "hugh", "bough", "broughton"
+	case i > 2 && m.at(i-1) == 'U' && m.is(i-3, "C", "G", "L", "R", "T"):
+		// "laugh", "cough", "tough"
+		m.add1("F")
+	case m.at(i-1) != 'I':
+		m.add1("K")
+	}
+	
+	return i + 2
+}
+
+
+func (m *doubleMetaphone) j(i int) int {
+	
+	if m.is(i, "JOSE") || m.is(0, "SAN ") {
+		// Spanish, as in "Jose" or "San Jacinto"
+		if i == 0 && m.at(i+4) == ' ' || len(m.value) == 4 || m.is(0, "SAN ") {
+			m.add1("H")
+		} else {
+			m.add("J", "H")
+		}
+		return i + 1
+	}
+	
+	switch {
+	case i == 0:
+		// "Yankelovich", "Jankelowicz"
+		m.add("J", "A")
+	case m.vowel(i-1) && !m.slavoGermanic && (m.at(i+1) == 'A' || m.at(i+1) == 'O'):
+		// Spanish, as in "bajador"
+		m.add("J", "H")
+	case i == len(m.value)-1:
+		m.add("J", "")
+	case !m.is(i+1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.is(i-1, "S", "K", "L"):
+		m.add1("J")
+	}
+	
+	return m.skip(i, "J")
+}
+
+
+func (m *doubleMetaphone) l(i int) int {
+	
+	if m.at(i+1) != 'L' {
+		m.add1("L")
+		return i + 1
+	}
+	
+	// Spanish, as in "cabrillo" or "gallegos"
+	last := len(m.value) - 1
+	if i == last-2 && m.is(i-1, "ILLO", "ILLA", "ALLE") ||
+		(m.is(last-1, "AS", "OS") || m.is(last, "A", "O")) && m.is(i-1, "ALLE") {
+		m.add("L", "")
+	} else {
+		m.add1("L")
+	}
+	return i + 2
+}
+
+
+func (m *doubleMetaphone) s(i int) int {
+	
+	switch {
+	case m.is(i-1, "ISL", "YSL"):
+		// silent, as in "island"
+		return i + 1
+	case i == 0 && m.is(i, "SUGAR"):
+		m.add("X", "S")
+		return i + 1
+	case m.is(i, "SH"):
+		// Germanic, as in "holmes"
+		if m.is(i+1, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.add1("S")
+		} else {
+			m.add1("X")
+		}
+		return i + 2
+	case m.is(i, "SIO", "SIA") || m.is(i, "SIAN"):
+		// Italian and Armenian
+		if m.slavoGermanic {
+			m.add1("S")
+		} else {
+			m.add("S", "X")
+		}
+		return i + 3
+	case i == 0 && m.is(i+1, "M", "N", "L", "W") || m.is(i+1, "Z"):
+		// German and Anglicised, as in "Smith" and "Schmidt"
+		m.add("S", "X")
+		return m.skip(i, "Z")
+	case m.is(i, "SC"):
+		return m.sc(i)
+	}
+	
+	// French, as in "resnais" or "artois"
+	if i == len(m.value)-1 && m.is(i-2, "AI", "OI") {
+		m.add("", "S")
+	} else {
+		m.add1("S")
+	}
+	return m.skip(i, "S", "Z")
+}
+
+
+func (m *doubleMetaphone) sc(i int) int {
+	
+	switch {
+	case m.at(i+2) == 'H':
+		switch {
+		case m.is(i+3, "ER", "EN"):
+			// Dutch, as in "schenker"
+			m.add("X", "SK")
+		case m.is(i+3, "OO", "UY", "ED", "EM"):
+			// "school", "schooner"
+			m.add1("SK")
+		case i == 0 && !m.vowel(3) && m.at(3) != 'W':
+			m.add("X", "S")
+		default:
+			m.add1("X")
+		}
+	case m.is(i+2, "I", "E", "Y"):
+		m.add1("S")
+	default:
+		m.add1("SK")
+	}
+	
+	return i + 3
+}
+
+
+func (m *doubleMetaphone) t(i int) int {
+	
+	switch {
+	case m.is(i, "TION") || m.is(i, "TIA", "TCH"):
+		m.add1("X")
+		return i + 3
+	case m.is(i, "TH") || m.is(i, "TTH"):
+		// "Thomas", "Thames"
+		if m.is(i+2, "OM", "AM") || m.is(0, "VAN ", "VON ") || m.is(0, "SCH") {
+			m.add1("T")
+		} else {
+			m.add("0", "T")
+		}
+		return i + 2
+	}
+	
+	m.add1("T")
+	return m.skip(i, "T", "D")
+}
+
+
+func (m *doubleMetaphone) w(i int) int {
+	
+	switch {
+	case m.is(i, "WR"):
+		m.add1("R")
+		return i + 2
+	case i == 0 && (m.vowel(i+1) || m.is(i, "WH")):
+		// "Wasserman" may also be "Vasserman"
+		if m.vowel(i + 1) {
+			m.add("A", "F")
+		} else {
+			m.add1("A")
+		}
+	case i == len(m.value)-1 && m.vowel(i-1) || m.is(i-1, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.is(0, "SCH"):
+		// Polish, as in "filipowicz"
+		m.add("", "F")
+	case m.is(i, "WICZ", "WITZ"):
+		m.add("TS", "FX")
+		return i + 4
+	}
+	
+	return i + 1
+}
+
+
+func (m *doubleMetaphone) z(i int) int {
+	
+	if m.at(i+1) == 'H' {
+		// Chinese pinyin, as in "Zhao"
+		m.add1("J")
+		return i + 2
+	}
+	
+	if m.is(i+1, "ZO", "ZI", "ZA") || m.slavoGermanic && i > 0 && m.at(i-1) != 'T' {
+		m.add("S", "TS")
+	} else {
+		m.add1("S")
+	}
+	return m.skip(i, "Z")
+}
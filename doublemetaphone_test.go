@@ -0,0 +1,68 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+type doubleMetaphoneTest struct {
+	in, primary, alternate string
+}
+
+var doubleMetaphoneTests = []doubleMetaphoneTest {
+	doubleMetaphoneTest{"Smith", "SM0", "XMT"},
+	doubleMetaphoneTest{"Schmidt", "XMT", "SMT"},
+	doubleMetaphoneTest{"Catherine", "K0RN", "KTRN"},
+	doubleMetaphoneTest{"Katherine", "K0RN", "KTRN"},
+	doubleMetaphoneTest{"Thompson", "TMPS", "TMPS"},
+	doubleMetaphoneTest{"Jose", "HS", "HS"},
+	doubleMetaphoneTest{"Jankelowicz", "JNKL", "ANKL"},
+	doubleMetaphoneTest{"Tagliaro", "TKLR", "TLR"},
+	doubleMetaphoneTest{"Caesar", "SSR", "SSR"},
+	doubleMetaphoneTest{"Chorus", "KRS", "KRS"},
+	doubleMetaphoneTest{"Laugh", "LF", "LF"},
+	doubleMetaphoneTest{"Wasserman", "ASRM", "FSRM"},
+	doubleMetaphoneTest{"Filipowicz", "FLPT", "FLPF"},
+	doubleMetaphoneTest{"Peña", "PN", "PN"},
+	doubleMetaphoneTest{"", "", ""},
+}
+
+func TestDoubleMetaphone(t *testing.T) {
+	for _, dt := range doubleMetaphoneTests {
+		primary, alternate := DoubleMetaphone(dt.in)
+		if primary != dt.primary || alternate != dt.alternate {
+			t.Errorf("DoubleMetaphone(%s) = `%s`, `%s`, want `%s`, `%s`",
+				dt.in, primary, alternate, dt.primary, dt.alternate)
+		}
+	}
+}
+
+func TestDoubleMetaphoneCollide(t *testing.T) {
+	p1, a1 := DoubleMetaphone("Smith")
+	p2, a2 := DoubleMetaphone("Schmidt")
+	checkState(t, p1 != p2, "primaries should differ")
+	checkString(t, a1, p2, "Smith's alternate should collide with Schmidt")
+	checkState(t, a2 != a1, "alternates should differ")
+
+	p1, a1 = DoubleMetaphone("Catherine")
+	p2, a2 = DoubleMetaphone("Katherine")
+	checkString(t, p1, p2, "primaries should collide")
+	checkString(t, a1, a2, "alternates should collide")
+
+	checkString(t, first(DoubleMetaphone("Müller")), first(DoubleMetaphone("Muller")), "accents should be stripped")
+}
+
+func first(primary, alternate string) string {
+	return primary
+}
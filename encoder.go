@@ -0,0 +1,188 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+
+/**
+ * Encoder is a named phonetic algorithm, turning a name into its code.
+ * Helpers which work over "any algorithm" take an Encoder.
+ */
+type Encoder interface {
+	Name() string
+	Encode(text string) string
+}
+
+
+type funcEncoder struct {
+	name string
+	fn   func(string) string
+}
+
+func (e *funcEncoder) Name() string {
+	return e.name
+}
+
+func (e *funcEncoder) Encode(text string) string {
+	return e.fn(text)
+}
+
+
+/**
+ * Wrap a plain encoding function as an Encoder called name.
+ */
+func NewEncoder(name string, fn func(string) string) Encoder {
+	return &funcEncoder{name, fn}
+}
+
+
+// Encoders for the algorithms in this package.
+var (
+	SoundexEncoder = NewEncoder("soundex", func(text string) string {
+		return Soundex(text, 4)
+	})
+	CaverphoneEncoder = NewEncoder("caverphone", Caverphone)
+	CologneEncoder    = NewEncoder("cologne", ColognePhonetic)
+	MetaphoneEncoder  = NewEncoder("metaphone", func(text string) string {
+		return MetaphoneN(text, 4)
+	})
+	FuzzySoundexEncoder = NewEncoder("fuzzysoundex", func(text string) string {
+		return FuzzySoundex(text, 5)
+	})
+)
+
+
+// Encoders known by name to GetEncoder, extended by RegisterEncoder.
+var registry = map[string]Encoder{
+	"soundex":      SoundexEncoder,
+	"caverphone":   CaverphoneEncoder,
+	"cologne":      CologneEncoder,
+	"metaphone":    MetaphoneEncoder,
+	"fuzzysoundex": FuzzySoundexEncoder,
+}
+
+
+/**
+ * Make e available from GetEncoder as name, e.g. for a proprietary
+ * coding scheme. Returns an error if name is already taken, by the
+ * encoders of this package or an earlier registration. Not safe to call
+ * while looking up encoders in other goroutines.
+ */
+func RegisterEncoder(name string, e Encoder) os.Error {
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("encoder %q is already registered", name)
+	}
+	registry[name] = e
+	return nil
+}
+
+
+// Forget a name added by RegisterEncoder, e.g. once a test is done.
+func unregisterEncoder(name string) {
+	registry[name] = nil, false
+}
+
+
+/**
+ * Look up an encoder by name: one of this package's ("soundex",
+ * "caverphone", "cologne", "metaphone", "fuzzysoundex") or one added by
+ * RegisterEncoder. Returns nil if name is unknown.
+ */
+func GetEncoder(name string) Encoder {
+	return registry[name]
+}
+
+
+/**
+ * Encode at most the first maxRunes runes of s with enc, bounding the
+ * work done on overly long input. A maxRunes of zero or less means no
+ * limit.
+ */
+func EncodeLimit(s string, enc Encoder, maxRunes int) string {
+	if maxRunes > 0 {
+		n := 0
+		for i := range s {
+			if n == maxRunes {
+				s = s[:i]
+				break
+			}
+			n++
+		}
+	}
+	return enc.Encode(s)
+}
+
+
+/**
+ * Encode a name which may be stored inverted as "Last, First": the part
+ * before the first comma is moved to the end, then each word is encoded
+ * with enc and the codes joined by spaces. "Smith, John" thus encodes
+ * as "John Smith" does. A name without a comma keeps its order.
+ */
+func EncodeInverted(name string, enc Encoder) string {
+	
+	if i := strings.Index(name, ","); i >= 0 {
+		name = name[i+1:] + " " + name[:i]
+	}
+	
+	return encodeWords(strings.Fields(name), enc)
+}
+
+
+/**
+ * Common English articles, prepositions and conjunctions, in lower case,
+ * for use as the stopwords of EncodePhraseFiltered.
+ */
+var EnglishStopwords = map[string]bool{
+	"a": true, "an": true, "the": true,
+	"of": true, "in": true, "on": true, "at": true, "for": true,
+	"to": true, "by": true, "with": true, "from": true,
+	"and": true, "or": true, "&": true,
+}
+
+
+/**
+ * Encode each word of text with enc, joining the codes by spaces, but
+ * leave out the words in stopwords, ignoring case and trailing periods
+ * or commas. "The Bank of America" thus encodes as "Bank America" does
+ * with EnglishStopwords.
+ */
+func EncodePhraseFiltered(text string, enc Encoder, stopwords map[string]bool) string {
+	
+	words := strings.Fields(text)
+	kept := make([]string, 0, len(words))
+	
+	for _, word := range words {
+		if !stopwords[strings.ToLower(strings.TrimRight(word, ".,"))] {
+			kept = append(kept, word)
+		}
+	}
+	
+	return encodeWords(kept, enc)
+}
+
+
+func encodeWords(words []string, enc Encoder) string {
+	
+	codes := make([]string, len(words))
+	
+	for i, word := range words {
+		codes[i] = enc.Encode(word)
+	}
+	
+	return strings.Join(codes, " ")
+}
@@ -0,0 +1,64 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+	"testing"
+)
+
+
+func TestEncodeLimit(t *testing.T) {
+	long := "Robert" + strings.Repeat("xyz", 1000)
+	checkString(t, EncodeLimit(long, SoundexEncoder, 6), Soundex("Robert", 4), "should encode the prefix")
+	checkString(t, EncodeLimit(long, CaverphoneEncoder, 4), Caverphone("Robe"), "should encode the prefix")
+	checkString(t, EncodeLimit("Robert", SoundexEncoder, 0), Soundex("Robert", 4), "zero should not truncate")
+	checkString(t, EncodeLimit("Müller", CologneEncoder, 2), ColognePhonetic("Mü"), "should count runes, not bytes")
+}
+
+func TestEncodeInverted(t *testing.T) {
+	checkString(t, EncodeInverted("Smith, John", SoundexEncoder), EncodeInverted("John Smith", SoundexEncoder), "should swap to first last order")
+	checkString(t, EncodeInverted("John Smith", SoundexEncoder), "J500 S530", "should encode each word")
+	checkString(t, EncodeInverted("Smith,John", SoundexEncoder), "J500 S530", "should not need a space after the comma")
+	checkString(t, EncodeInverted("Lee", SoundexEncoder), "L000", "should encode a single name as is")
+}
+
+func TestEncodePhraseFiltered(t *testing.T) {
+	checkString(t, EncodePhraseFiltered("The Acme Corp", SoundexEncoder, EnglishStopwords),
+		EncodePhraseFiltered("Acme Corp", SoundexEncoder, EnglishStopwords), "should drop the article")
+	checkString(t, EncodePhraseFiltered("The Bank of America", SoundexEncoder, EnglishStopwords), "B520 A562",
+		"should drop every stopword")
+	checkString(t, EncodePhraseFiltered("THE Acme, Inc.", SoundexEncoder, map[string]bool{"the": true, "inc": true}),
+		"A250", "should ignore case and trailing punctuation")
+	checkString(t, EncodePhraseFiltered("The Acme", SoundexEncoder, nil), "T000 A250", "nil stopwords should keep every word")
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	checkState(t, GetEncoder("soundex") == SoundexEncoder, "should know the package's encoders")
+	checkState(t, GetEncoder("initials") == nil, "should not know an unregistered name")
+
+	initials := NewEncoder("initials", func(text string) string {
+		return strings.ToUpper(text[:1])
+	})
+	checkState(t, RegisterEncoder("initials", initials) == nil, "should register a new name")
+	defer unregisterEncoder("initials")
+	checkState(t, RegisterEncoder("initials", initials) != nil, "should refuse a duplicate name")
+	checkState(t, RegisterEncoder("soundex", initials) != nil, "should refuse a built-in name")
+
+	enc := GetEncoder("initials")
+	checkState(t, enc != nil, "should find the registered encoder")
+	if enc != nil {
+		checkString(t, enc.Encode("robert"), "R", "should encode with the custom algorithm")
+		matched, by := TieredMatch("Robert", "Rupert", []Encoder{enc})
+		checkState(t, matched && by == "initials", "should be usable by name in TieredMatch")
+	}
+}
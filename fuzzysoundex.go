@@ -0,0 +1,103 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+)
+
+
+// Digits of A to Z for Fuzzy Soundex; '-' marks H, W and Y, which are
+// not coded.
+var fuzzyDigits string = "0193017-07745501769301-7-9"
+
+
+// Letter groups rewritten before coding, in order.
+var fuzzyRules = [][2]string{
+	{"CA", "KA"}, {"CC", "KK"}, {"CK", "KK"}, {"CE", "SE"}, {"CHL", "KL"},
+	{"CL", "KL"}, {"CHR", "KR"}, {"CR", "KR"}, {"CI", "SI"}, {"CO", "KO"},
+	{"CU", "KU"}, {"CY", "SY"}, {"DG", "GG"}, {"GH", "HH"}, {"MAC", "MK"},
+	{"MC", "MK"}, {"NST", "NSS"}, {"PF", "FF"}, {"PH", "FF"}, {"SCH", "SSS"},
+	{"TIO", "SIO"}, {"TIA", "SIO"}, {"TCH", "CHH"},
+}
+
+
+/**
+ * This is Fuzzy Soundex, padded or cut to length.
+ * based on: David Holmes and M. Catherine McCabe, "Improving Precision
+ * and Recall for Soundex Retrieval", 2002.
+ * It rewrites letter groups that sound alike (PH as FF, KN as NN, ...)
+ * before coding, so it copes better with misread or misspelled names.
+ */
+func FuzzySoundex(name string, length int) string {
+	
+	word := strings.ToUpper(lowerAlpha(name))
+	
+	if len(word) == 0 {
+		return ""
+	}
+	
+	switch {
+	case hasAnyPrefix(word, "CS", "CZ", "TS", "TZ"):
+		word = "SS" + word[2:]
+	case strings.HasPrefix(word, "GN"):
+		word = "NN" + word[2:]
+	case hasAnyPrefix(word, "HR", "WR"):
+		word = "RR" + word[2:]
+	case strings.HasPrefix(word, "HW"):
+		word = "WW" + word[2:]
+	case hasAnyPrefix(word, "KN", "NG"):
+		word = "NN" + word[2:]
+	}
+	
+	switch {
+	case strings.HasSuffix(word, "CH"):
+		word = word[:len(word)-2] + "KK"
+	case strings.HasSuffix(word, "NT"):
+		word = word[:len(word)-2] + "TT"
+	case strings.HasSuffix(word, "RT"):
+		word = word[:len(word)-2] + "RR"
+	case strings.HasSuffix(word, "RDT"):
+		word = word[:len(word)-3] + "RR"
+	}
+	
+	for _, r := range fuzzyRules {
+		word = strings.Replace(word, r[0], r[1], -1)
+	}
+	
+	sndx := ""
+	
+	for i := 0; i < len(word); i++ {
+		d := fuzzyDigits[word[i]-'A']
+		if d != '-' && (sndx == "" || d != sndx[len(sndx)-1]) {
+			sndx += string(d)
+		}
+	}
+	
+	if byteIn(word[0], "HWY") {
+		sndx = word[:1] + sndx
+	} else {
+		sndx = word[:1] + sndx[1:]
+	}
+	
+	return padSoundex(strings.Replace(sndx, "0", "", -1), length)
+}
+
+
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
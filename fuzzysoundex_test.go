@@ -0,0 +1,29 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestFuzzySoundex(t *testing.T) {
+	checkString(t, FuzzySoundex("Kristen", 5), "K6935", "should code")
+	checkString(t, FuzzySoundex("Christen", 5), "K6935", "CHR should sound as KR")
+	checkString(t, FuzzySoundex("Phillips", 5), FuzzySoundex("Filips", 5), "PH should sound as F")
+	checkString(t, FuzzySoundex("Knight", 5), "N3000", "KN should sound as N")
+	checkString(t, FuzzySoundex("Wright", 5), FuzzySoundex("Rite", 5), "WR should sound as R")
+	checkString(t, FuzzySoundex("Catherine", 5), FuzzySoundex("Katherine", 5), "CA should sound as KA")
+	checkString(t, FuzzySoundex("Yates", 5), "Y3900", "should keep an uncoded initial")
+	checkString(t, FuzzySoundex("Lee", 4), "L000", "should pad with zeros")
+	checkString(t, FuzzySoundex("", 5), "", "blank name should give blank code")
+}
@@ -0,0 +1,108 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+
+/**
+ * Group names by their code under enc. Names within a bucket keep
+ * their input order. Names which encode to "" are left out.
+ */
+func BuildIndex(names []string, enc Encoder) map[string][]string {
+	
+	index := make(map[string][]string)
+	
+	for _, name := range names {
+		code := enc.Encode(name)
+		if code == "" {
+			continue
+		}
+		index[code] = append(index[code], name)
+	}
+	
+	return index
+}
+
+
+/**
+ * Like BuildIndex, but returns the groups themselves, ordered by the
+ * first appearance of each code in names. The result is the same on
+ * every run for the same input.
+ */
+func Cluster(names []string, enc Encoder) [][]string {
+	
+	pos := make(map[string]int)
+	rv := make([][]string, 0)
+	
+	for _, name := range names {
+		code := enc.Encode(name)
+		if code == "" {
+			continue
+		}
+		i, ok := pos[code]
+		if !ok {
+			i = len(rv)
+			pos[code] = i
+			rv = append(rv, nil)
+		}
+		rv[i] = append(rv[i], name)
+	}
+	
+	return rv
+}
+
+
+/**
+ * Join two name lists by code: maps each name in left to the names in
+ * right which share its code under enc, in right's order. Names in
+ * left without any candidate are left out.
+ */
+func JoinByPhonetic(left, right []string, enc Encoder) map[string][]string {
+	
+	index := BuildIndex(right, enc)
+	rv := make(map[string][]string)
+	
+	for _, name := range left {
+		if candidates, ok := index[enc.Encode(name)]; ok {
+			rv[name] = candidates
+		}
+	}
+	
+	return rv
+}
+
+
+/**
+ * Union indexes as made by BuildIndex, e.g. one per shard of the input.
+ * Each bucket holds the names of that code from every index, in the
+ * order of indexes then of their buckets, with repeats left out.
+ */
+func MergeIndexes(indexes ...map[string][]string) map[string][]string {
+	
+	rv := make(map[string][]string)
+	seen := make(map[string]map[string]bool)
+	
+	for _, index := range indexes {
+		for code, names := range index {
+			if seen[code] == nil {
+				seen[code] = make(map[string]bool)
+			}
+			for _, name := range names {
+				if !seen[code][name] {
+					seen[code][name] = true
+					rv[code] = append(rv[code], name)
+				}
+			}
+		}
+	}
+	
+	return rv
+}
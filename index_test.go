@@ -0,0 +1,62 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+var indexNames = []string{"Robert", "Smith", "Rupert", "Lee", "Smyth", "Rubin", "Smithe"}
+
+func TestBuildIndex(t *testing.T) {
+	index := BuildIndex(indexNames, SoundexEncoder)
+	checkCapture(t, []string{"Robert", "Rupert"}, index["R163"], "should group R163 in input order")
+	checkCapture(t, []string{"Smith", "Smyth", "Smithe"}, index["S530"], "should group S530 in input order")
+	checkState(t, len(index) == 4, "should have four codes")
+}
+
+func TestClusterOrder(t *testing.T) {
+	expected := [][]string{
+		[]string{"Robert", "Rupert"},
+		[]string{"Smith", "Smyth", "Smithe"},
+		[]string{"Lee"},
+		[]string{"Rubin"},
+	}
+	for run := 0; run < 20; run++ {
+		clusters := Cluster(indexNames, SoundexEncoder)
+		checkState(t, len(clusters) == len(expected), "should have four clusters")
+		for i := 0; i < len(clusters) && i < len(expected); i++ {
+			checkCapture(t, expected[i], clusters[i], "cluster order should be stable")
+		}
+	}
+}
+
+func TestJoinByPhonetic(t *testing.T) {
+	left := []string{"Robert", "Smith", "Jones"}
+	right := []string{"Smyth", "Rupert", "Rubin", "Smithe"}
+	join := JoinByPhonetic(left, right, SoundexEncoder)
+	checkState(t, len(join) == 2, "Jones should have no candidates")
+	checkCapture(t, []string{"Rupert"}, join["Robert"], "Robert should join Rupert")
+	checkCapture(t, []string{"Smyth", "Smithe"}, join["Smith"], "Smith should join Smyth and Smithe")
+}
+
+func TestMergeIndexes(t *testing.T) {
+	a := BuildIndex(indexNames[:4], SoundexEncoder)
+	b := BuildIndex(append([]string{"Robert"}, indexNames[4:]...), SoundexEncoder)
+	merged := MergeIndexes(a, b)
+	checkCapture(t, []string{"Robert", "Rupert"}, merged["R163"], "should drop the repeated Robert")
+	checkCapture(t, []string{"Smith", "Smyth", "Smithe"}, merged["S530"], "should keep shard order")
+	checkCapture(t, []string{"Rubin"}, merged["R150"], "should keep codes of one shard")
+	checkState(t, len(merged) == 4, "should have four codes")
+	checkState(t, len(MergeIndexes()) == 0, "no indexes should merge to an empty index")
+}
@@ -0,0 +1,57 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+
+/**
+ * Interner hands out one shared copy of each distinct string, so that
+ * large indexes holding the same code many times only store it once.
+ * An Interner is not safe for concurrent use.
+ */
+type Interner struct {
+	strs map[string]string
+}
+
+
+func NewInterner() *Interner {
+	return &Interner{make(map[string]string)}
+}
+
+
+/**
+ * Return the shared copy of s, remembering s if it's the first of its
+ * value.
+ */
+func (in *Interner) Intern(s string) string {
+	if rv, ok := in.strs[s]; ok {
+		return rv
+	}
+	in.strs[s] = s
+	return s
+}
+
+
+// Number of distinct strings held.
+func (in *Interner) Len() int {
+	return len(in.strs)
+}
+
+
+/**
+ * Wrap enc so every code it returns is interned, e.g. for use with
+ * BuildIndex.
+ */
+func (in *Interner) Encoder(enc Encoder) Encoder {
+	return NewEncoder(enc.Name(), func(text string) string {
+		return in.Intern(enc.Encode(text))
+	})
+}
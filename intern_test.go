@@ -0,0 +1,42 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestInterner(t *testing.T) {
+	in := NewInterner()
+	a := in.Intern(Soundex("Robert", 4))
+	b := in.Intern(Soundex("Rupert", 4))
+	checkString(t, b, a, "codes should be equal")
+	checkState(t, stringData(a) == stringData(b), "equal codes should share storage")
+	checkState(t, in.Len() == 1, "should hold a single string")
+
+	enc := in.Encoder(SoundexEncoder)
+	checkString(t, enc.Name(), "soundex", "should keep the encoder name")
+	c := enc.Encode("Robin")
+	d := enc.Encode("Rubin")
+	checkState(t, stringData(c) == stringData(d), "wrapped encoder should intern")
+
+	index := BuildIndex([]string{"Robert", "Rupert", "Robin"}, enc)
+	checkState(t, len(index) == 2, "should index through the wrapped encoder")
+	checkState(t, in.Len() == 2, "should hold two strings")
+}
@@ -0,0 +1,175 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"math"
+	"strings"
+)
+
+
+// Codes which SoundexMatch and CaverphoneMatch never match on.
+var collisionBlocklist map[string]bool
+
+
+/**
+ * Mark codes, of any algorithm in this package, as too prone to false
+ * positives to match on: SoundexMatch and CaverphoneMatch return false
+ * for names sharing one of them. Replaces any previous blocklist; nil
+ * clears it. Not safe to call while matching in other goroutines.
+ */
+func SetCollisionBlocklist(codes map[string]bool) {
+	collisionBlocklist = codes
+}
+
+
+func codesMatch(a, b string, enc Encoder) bool {
+	code := enc.Encode(a)
+	return code != "" && code == enc.Encode(b) && !collisionBlocklist[code]
+}
+
+
+/**
+ * Whether a and b share a Soundex code which is not blocklisted (see
+ * SetCollisionBlocklist).
+ */
+func SoundexMatch(a, b string) bool {
+	return codesMatch(a, b, SoundexEncoder)
+}
+
+
+/**
+ * Whether a and b share a Caverphone code which is not blocklisted (see
+ * SetCollisionBlocklist).
+ */
+func CaverphoneMatch(a, b string) bool {
+	return codesMatch(a, b, CaverphoneEncoder)
+}
+
+
+/**
+ * Whether a and b encode identically under enc, once each is lower
+ * cased and its runs of white space are cut to single spaces. Unlike
+ * SoundexMatch no code is blocklisted, but a blank code never matches.
+ * The algorithm is picked by an Encoder rather than a separate enum, as
+ * elsewhere in this package: SoundexEncoder, MetaphoneEncoder, one from
+ * GetEncoder, or any custom Encoder.
+ */
+func PhoneticEqual(a, b string, enc Encoder) bool {
+	
+	code := enc.Encode(normalizeSpace(a))
+	
+	return code != "" && code == enc.Encode(normalizeSpace(b))
+}
+
+
+/**
+ * Whether a and b sound alike, by PhoneticEqual under Soundex.
+ */
+func SoundsLike(a, b string) bool {
+	return PhoneticEqual(a, b, SoundexEncoder)
+}
+
+
+// s lower cased, trimmed, and with each run of white space made one space.
+func normalizeSpace(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+
+/**
+ * Try each of encoders in turn, stopping at the first under which a and
+ * b match as for SoundexMatch, and report that encoder's name. Returns
+ * false and "" if none match. This suits cascades such as "Soundex,
+ * else Metaphone, else give up".
+ */
+func TieredMatch(a, b string, encoders []Encoder) (matched bool, byEncoder string) {
+	
+	for _, enc := range encoders {
+		if codesMatch(a, b, enc) {
+			return true, enc.Name()
+		}
+	}
+	
+	return false, ""
+}
+
+
+/**
+ * Confidence, from 0 to 1, that a and b match under enc, weighting the
+ * shared code by its rarity: codeFreq gives how many of a corpus of
+ * total names have each code. A code no name has scores 1, and one
+ * every name has scores 0, scaling by inverse document frequency in
+ * between. Returns 0 unless a and b match as for SoundexMatch.
+ */
+func WeightedMatch(a, b string, enc Encoder, codeFreq map[string]int, total int) float64 {
+	
+	if !codesMatch(a, b, enc) || total <= 0 {
+		return 0
+	}
+	
+	freq := codeFreq[enc.Encode(a)]
+	if freq > total {
+		freq = total
+	}
+	
+	return math.Log(float64(total+1)/float64(freq+1)) / math.Log(float64(total+1))
+}
+
+
+/**
+ * Whether the Soundex codes of a and b, padded or cut to length, differ
+ * in at most maxMismatch places, catching pairs with a single slip of
+ * transcription: Bart (B630) and Bald (B430) match with maxMismatch 1,
+ * though not with 0, which is SoundexMatch. A blank or blocklisted
+ * code (see SetCollisionBlocklist) never matches.
+ */
+func SoundexMatchTolerant(a, b string, length int, maxMismatch int) bool {
+	
+	ca, cb := Soundex(a, length), Soundex(b, length)
+	
+	if ca == "" || cb == "" || collisionBlocklist[ca] || collisionBlocklist[cb] {
+		return false
+	}
+	
+	return length-samePlaces(ca, cb) <= maxMismatch
+}
+
+
+/**
+ * How alike the four character Soundex codes of a and b are, from 0 to
+ * 4, as the DIFFERENCE function of SQL Server: the number of places in
+ * which the codes agree. Green and Greene (G650) give 4, Blotchet-Halls
+ * (B432) and Greene give 0. A name without letters gives 0.
+ */
+func SoundexDifference(a, b string) int {
+	
+	ca, cb := Soundex(a, 4), Soundex(b, 4)
+	
+	if ca == "" || cb == "" {
+		return 0
+	}
+	
+	return samePlaces(ca, cb)
+}
+
+
+// Number of places in which the equally long codes a and b agree.
+func samePlaces(a, b string) int {
+	n := 0
+	for i := 0; i < len(a); i++ {
+		if a[i] == b[i] {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,83 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestCollisionBlocklist(t *testing.T) {
+	checkState(t, SoundexMatch("Robert", "Rupert"), "should match on a shared code")
+	checkState(t, !SoundexMatch("Robert", "Smith"), "should not match on different codes")
+	checkState(t, CaverphoneMatch("Henrichsen", "Hinrichsen"), "should match on a shared code")
+
+	SetCollisionBlocklist(map[string]bool{"R163": true, "ANRKSN1111": true})
+	defer SetCollisionBlocklist(nil)
+	checkState(t, !SoundexMatch("Robert", "Rupert"), "should not match on a blocklisted code")
+	checkState(t, SoundexMatch("Smith", "Smyth"), "should still match on other codes")
+	checkState(t, !CaverphoneMatch("Henrichsen", "Hinrichsen"), "should not match on a blocklisted code")
+	checkState(t, CaverphoneMatch("mayer", "meier"), "should still match on other codes")
+
+	SetCollisionBlocklist(nil)
+	checkState(t, SoundexMatch("Robert", "Rupert"), "clearing should match again")
+	checkState(t, !SoundexMatch("", "123"), "should not match on an empty code")
+}
+
+func TestTieredMatch(t *testing.T) {
+	tiers := []Encoder{SoundexEncoder, MetaphoneEncoder}
+	matched, by := TieredMatch("Robert", "Rupert", tiers)
+	checkState(t, matched && by == "soundex", "should stop at the first tier")
+	matched, by = TieredMatch("Knight", "Night", tiers)
+	checkState(t, matched && by == "metaphone", "should fall back to metaphone")
+	matched, by = TieredMatch("Robert", "Smith", tiers)
+	checkState(t, !matched && by == "", "should give up after the last tier")
+	matched, by = TieredMatch("Robert", "Rupert", nil)
+	checkState(t, !matched && by == "", "no tiers should not match")
+}
+
+func TestWeightedMatch(t *testing.T) {
+	freq := map[string]int{"S530": 900, "R163": 3}
+	rare := WeightedMatch("Robert", "Rupert", SoundexEncoder, freq, 1000)
+	common := WeightedMatch("Smith", "Smyth", SoundexEncoder, freq, 1000)
+	checkState(t, rare > common, "a rare shared code should score higher than a common one")
+	checkState(t, common > 0 && rare < 1, "scores should be between 0 and 1")
+	checkState(t, WeightedMatch("Lee", "Li", SoundexEncoder, freq, 1000) == 1, "an unseen code should score 1")
+	checkState(t, WeightedMatch("Smith", "Smyth", SoundexEncoder, map[string]int{"S530": 1000}, 1000) == 0,
+		"a code every name has should score 0")
+	checkState(t, WeightedMatch("Robert", "Smith", SoundexEncoder, freq, 1000) == 0, "different codes should score 0")
+}
+
+func TestSoundexMatchTolerant(t *testing.T) {
+	checkState(t, SoundexMatchTolerant("Bart", "Bald", 4, 1), "one differing digit should match with tolerance 1")
+	checkState(t, !SoundexMatchTolerant("Bart", "Bald", 4, 0), "one differing digit should not match with tolerance 0")
+	checkState(t, SoundexMatchTolerant("Robert", "Rupert", 4, 0), "equal codes should match with tolerance 0")
+	checkState(t, !SoundexMatchTolerant("Bart", "Lee", 4, 2), "codes differing everywhere should not match")
+	checkState(t, !SoundexMatchTolerant("", "Lee", 4, 4), "blank code should never match")
+}
+
+func TestSoundexDifference(t *testing.T) {
+	checkState(t, SoundexDifference("Green", "Greene") == 4, "same codes should give 4")
+	checkState(t, SoundexDifference("Blotchet-Halls", "Greene") == 0, "B432 and G650 should give 0")
+	checkState(t, SoundexDifference("Bart", "Bald") == 3, "B630 and B430 should give 3")
+	checkState(t, SoundexDifference("Robert", "Rupert") == 4, "should ignore vowels")
+	checkState(t, SoundexDifference("", "Lee") == 0, "blank name should give 0")
+}
+
+func TestPhoneticEqual(t *testing.T) {
+	checkState(t, SoundsLike("Robert", "Rupert"), "should sound alike under Soundex")
+	checkState(t, PhoneticEqual("Robert", "Rupert", SoundexEncoder), "should match under Soundex")
+	checkState(t, !PhoneticEqual("Robert", "Rupert", MetaphoneEncoder), "should diverge under Metaphone")
+	checkState(t, PhoneticEqual("  ROBERT ", "robert", MetaphoneEncoder), "should ignore case and outer space")
+	checkState(t, PhoneticEqual("Henrichsen", "hinrichsen", CaverphoneEncoder), "should match under Caverphone")
+	checkState(t, !SoundsLike("", ""), "blank names should not sound alike")
+}
@@ -0,0 +1,177 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+)
+
+
+func isVowel(c byte) bool {
+	return byteIn(c, "AEIOU")
+}
+
+
+/**
+ * This is the original Metaphone algorithm, by Lawrence Philips, 1990,
+ * giving the whole upper case key of word. "0" stands for the "th"
+ * sound, "X" for "sh"; vowels are only kept when leading.
+ */
+func Metaphone(word string) string {
+	return MetaphoneN(word, 0)
+}
+
+
+/**
+ * Metaphone key of text cut to maxLen characters, classically 4; a
+ * maxLen of zero or less means no limit.
+ */
+func MetaphoneN(text string, maxLen int) string {
+	
+	w := make([]byte, 0, len(text))
+	
+	for _, c := range strings.ToUpper(text) {
+		if c >= 'A' && c <= 'Z' {
+			w = append(w, byte(c))
+		}
+	}
+	
+	if len(w) == 0 {
+		return ""
+	}
+	
+	// initial letter exceptions
+	if len(w) > 1 {
+		switch string(w[:2]) {
+		case "AE", "GN", "KN", "PN", "WR":
+			w = w[1:]
+		case "WH":
+			w = append([]byte{'W'}, w[2:]...)
+		}
+	}
+	if w[0] == 'X' {
+		w[0] = 'S'
+	}
+	
+	at := func(i int) byte {
+		if i < 0 || i >= len(w) {
+			return 0
+		}
+		return w[i]
+	}
+	last := len(w) - 1
+	code := make([]byte, 0, len(w)+1)
+	
+	for i := 0; i <= last && (maxLen <= 0 || len(code) < maxLen); i++ {
+		
+		c := w[i]
+		if c != 'C' && c == at(i-1) {
+			continue
+		}
+		prev, next, next2 := at(i-1), at(i+1), at(i+2)
+		
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U':
+			if i == 0 {
+				code = append(code, c)
+			}
+		case 'B':
+			if !(prev == 'M' && i == last) {
+				code = append(code, 'B')
+			}
+		case 'C':
+			switch {
+			case prev == 'S' && byteIn(next, "EIY"):
+				// silent, as in "science"
+			case next == 'I' && next2 == 'A':
+				code = append(code, 'X')
+			case next == 'H' && prev != 'S':
+				code = append(code, 'X')
+			case byteIn(next, "EIY"):
+				code = append(code, 'S')
+			default:
+				code = append(code, 'K')
+			}
+		case 'D':
+			if next == 'G' && byteIn(next2, "EIY") {
+				code = append(code, 'J')
+			} else {
+				code = append(code, 'T')
+			}
+		case 'G':
+			switch {
+			case next == 'H' && i+1 != last && !isVowel(next2):
+				// silent, as in "night"
+			case next == 'N' && (i+1 == last || (i+3 == last && next2 == 'E' && at(i+3) == 'D')):
+				// silent, as in "sign" or "signed"
+			case prev == 'D' && byteIn(next, "EIY"):
+				// silent, coded by the D of "dge"
+			case byteIn(next, "EIY") && prev != 'G':
+				code = append(code, 'J')
+			default:
+				code = append(code, 'K')
+			}
+		case 'H':
+			if i != last && !byteIn(prev, "CSPTG") && isVowel(next) {
+				code = append(code, 'H')
+			}
+		case 'K':
+			if prev != 'C' {
+				code = append(code, 'K')
+			}
+		case 'P':
+			if next == 'H' {
+				code = append(code, 'F')
+			} else {
+				code = append(code, 'P')
+			}
+		case 'Q':
+			code = append(code, 'K')
+		case 'S':
+			if next == 'H' || (next == 'I' && byteIn(next2, "OA")) {
+				code = append(code, 'X')
+			} else {
+				code = append(code, 'S')
+			}
+		case 'T':
+			switch {
+			case next == 'I' && byteIn(next2, "OA"):
+				code = append(code, 'X')
+			case next == 'H':
+				code = append(code, '0')
+			case next == 'C' && next2 == 'H':
+				// silent, as in "watch"
+			default:
+				code = append(code, 'T')
+			}
+		case 'V':
+			code = append(code, 'F')
+		case 'W', 'Y':
+			if isVowel(next) {
+				code = append(code, c)
+			}
+		case 'X':
+			code = append(code, 'K', 'S')
+		case 'Z':
+			code = append(code, 'S')
+		default:
+			// F, J, L, M, N and R code as themselves
+			code = append(code, c)
+		}
+	}
+	
+	if maxLen > 0 && len(code) > maxLen {
+		code = code[:maxLen]
+	}
+	
+	return string(code)
+}
@@ -0,0 +1,58 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+type metaphoneTest struct {
+	in, out string
+}
+
+var metaphoneTests = []metaphoneTest {
+	metaphoneTest{"Thompson", "0MPSN"},
+	metaphoneTest{"Wright", "RT"},
+	metaphoneTest{"knight", "NT"},
+	metaphoneTest{"Gnome", "NM"},
+	metaphoneTest{"Whitlam", "WTLM"},
+	metaphoneTest{"Science", "SNS"},
+	metaphoneTest{"Church", "XRX"},
+	metaphoneTest{"Shaw", "X"},
+	metaphoneTest{"Aebersold", "EBRSLT"},
+}
+
+func TestMetaphoneTable(t *testing.T) {
+	for _, dt := range metaphoneTests {
+		rv := Metaphone(dt.in)
+		if rv != dt.out {
+			t.Errorf("Metaphone(%s) = `%s`, want `%s`", dt.in, rv, dt.out)
+		}
+	}
+	checkString(t, MetaphoneN("Thompson", 4), "0MPS", "should cut to maxLen")
+}
+
+
+func TestMetaphone(t *testing.T) {
+	checkString(t, MetaphoneN("Alexanderson", 4), "ALKS", "should cut to four")
+	checkString(t, MetaphoneN("Alexanderson", 0), "ALKSNTRSN", "zero should not truncate")
+	checkString(t, MetaphoneN("Alexanderson", -1), "ALKSNTRSN", "negative should not truncate")
+	checkString(t, MetaphoneN("Alexanderson", 3), "ALK", "should cut within the X's KS")
+	checkString(t, Metaphone("Knight"), "NT", "should drop initial K and silent GH")
+	checkString(t, Metaphone("Thumb"), "0M", "should code TH as 0 and drop final B")
+	checkString(t, Metaphone("Phillips"), "FLPS", "should code PH as F")
+	checkString(t, Metaphone("Xavier"), "SFR", "should code initial X as S")
+	checkString(t, Metaphone("Nation"), "NXN", "should code TIO as X")
+	checkString(t, Metaphone("Edge"), "EJ", "should code DGE as J")
+	checkString(t, MetaphoneN("", 4), "", "blank text should give blank code")
+}
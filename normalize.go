@@ -0,0 +1,195 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+
+/**
+ * Honorifics removed by StripTitles from the front of a name, in lower
+ * case and without any trailing period.
+ */
+var Titles = map[string]bool{
+	"dr": true, "mr": true, "mrs": true,
+}
+
+
+/**
+ * Generational suffixes removed by StripTitles from the end of a name,
+ * as for Titles.
+ */
+var Suffixes = map[string]bool{
+	"jr": true, "sr": true, "iii": true,
+}
+
+
+/**
+ * Remove the words in Titles from the front of name and those in
+ * Suffixes from its end, ignoring case and trailing periods or commas,
+ * e.g. "Dr. John Smith Jr." becomes "John Smith". Words elsewhere are
+ * kept, being likely part of the name. If nothing would be left, name
+ * is returned as it is.
+ */
+func StripTitles(name string) string {
+	
+	words := strings.Fields(name)
+	first, last := 0, len(words)
+	
+	for first < last && Titles[titleKey(words[first])] {
+		first++
+	}
+	for last > first && Suffixes[titleKey(words[last-1])] {
+		last--
+	}
+	
+	if first == last {
+		return name
+	}
+	return strings.Join(words[first:last], " ")
+}
+
+
+// word as looked up in Titles and Suffixes.
+func titleKey(word string) string {
+	return strings.ToLower(strings.TrimRight(word, ".,"))
+}
+
+
+type romanRule struct {
+	from, to string
+}
+
+
+/**
+ * Respellings applied by RomanizeNormalize, per romanization system.
+ * At each position the first rule whose from matches is used, so a
+ * longer cluster must come before any rule matching its start; rules
+ * mapping a cluster to itself keep it from being split.
+ */
+var romanRules = map[string][]romanRule{
+	// Pinyin, also folding the Wade-Giles spellings hs and ts, into
+	// English-like spellings: Xiao and Hsiao both become shiao.
+	"pinyin": {
+		{"zh", "j"}, {"ch", "ch"}, {"sh", "sh"}, {"hs", "sh"}, {"ts", "ts"},
+		{"x", "sh"}, {"q", "ch"}, {"c", "ts"},
+	},
+	// Hepburn romaji, also folding the Kunrei-shiki spellings and long
+	// vowels: Tuda and Tsuda become tsuda, Satou and Satō become sato.
+	"hepburn": {
+		{"sh", "sh"}, {"ch", "ch"}, {"ts", "ts"},
+		{"sy", "sh"}, {"ty", "ch"}, {"zy", "j"}, {"jy", "j"},
+		{"si", "shi"}, {"ti", "chi"}, {"tu", "tsu"}, {"hu", "fu"},
+		{"zi", "ji"}, {"di", "ji"}, {"du", "zu"},
+		{"ou", "o"}, {"oo", "o"}, {"uu", "u"}, {"ō", "o"}, {"ū", "u"},
+		{"mb", "nb"}, {"mp", "np"}, {"mm", "nm"},
+	},
+}
+
+
+/**
+ * Respell a romanized name s, in lower case, so that the spelling
+ * variants of system ("pinyin" or "hepburn") encode alike with the
+ * Latin encoders in this package. Returns s unchanged for any other
+ * system.
+ */
+func RomanizeNormalize(s string, system string) string {
+	
+	rules, ok := romanRules[system]
+	if !ok {
+		return s
+	}
+	
+	return applyRomanRules(strings.ToLower(s), rules)
+}
+
+
+/**
+ * German clusters respelled by GermanizeForSoundex, in the order
+ * tried, so "tsch" comes before the "sch" it ends with.
+ */
+var germanRules = []romanRule{
+	{"tsch", "ch"}, {"sch", "s"}, {"ck", "k"},
+}
+
+
+/**
+ * Respell the German clusters "tsch", "sch" and "ck" in s, in lower
+ * case, so that German names code sensibly with English Soundex:
+ * Schmidt becomes smidt, coding alike with Shmit.
+ */
+func GermanizeForSoundex(s string) string {
+	return applyRomanRules(strings.ToLower(s), germanRules)
+}
+
+
+/**
+ * Rewrite s with rules, using the first rule matching at each position
+ * and copying a byte unchanged where none do.
+ */
+func applyRomanRules(s string, rules []romanRule) string {
+	
+	rv := ""
+	
+	for i := 0; i < len(s); {
+		matched := false
+		for _, r := range rules {
+			if strings.HasPrefix(s[i:], r.from) {
+				rv += r.to
+				i += len(r.from)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			rv += s[i : i+1]
+			i++
+		}
+	}
+	
+	return rv
+}
+
+
+/**
+ * Glyphs which ConfusableFold replaces, mapped to the upper case letter
+ * each is commonly mistaken for, as in OCR'd or handwritten plates and
+ * SKUs. Keys are checked before upper casing, so lower case l can stand
+ * for I without affecting L.
+ */
+var Confusables = map[int]int{
+	'0': 'O', '1': 'I', 'l': 'I', '|': 'I', '!': 'I',
+	'2': 'Z', '5': 'S', '$': 'S', '6': 'G', '8': 'B',
+}
+
+
+/**
+ * Fold s to a canonical form for comparing identifiers which may mix
+ * up digits and letters: each rune in Confusables is replaced, and the
+ * rest upper cased. "IOI" and "101" thus both become "IOI".
+ */
+func ConfusableFold(s string) string {
+	
+	rv := make([]int, 0, len(s))
+	
+	for _, c := range s {
+		if to, ok := Confusables[c]; ok {
+			rv = append(rv, to)
+		} else {
+			rv = append(rv, unicode.ToUpper(c))
+		}
+	}
+	
+	return string(rv)
+}
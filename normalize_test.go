@@ -0,0 +1,71 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestStripTitles(t *testing.T) {
+	checkString(t, StripTitles("Dr. John Smith Jr."), "John Smith", "should strip honorific and suffix")
+	checkString(t, StripTitles("MRS Jane  Doe"), "Jane Doe", "should ignore case")
+	checkString(t, StripTitles("Henry Ford III"), "Henry Ford", "should strip numeral suffix")
+	checkString(t, StripTitles("Drake"), "Drake", "should keep names starting with a title")
+	checkString(t, Soundex(StripTitles("Mr. Robert"), 4), Soundex("Robert", 4), "should encode the bare name")
+	checkString(t, StripTitles("John Dr Smith"), "John Dr Smith", "should keep a title inside the name")
+	checkString(t, StripTitles("Jr Smith"), "Jr Smith", "should strip suffixes only from the end")
+	checkString(t, StripTitles("Smith Mr"), "Smith Mr", "should strip honorifics only from the front")
+	checkString(t, StripTitles("Naomasa Ii"), "Naomasa Ii", "should keep surnames that look like numerals")
+	checkString(t, StripTitles("Sir"), "Sir", "a name made only of titles should survive")
+	checkString(t, StripTitles("Dr. Jr."), "Dr. Jr.", "a name made only of titles should survive")
+}
+
+func TestRomanizeNormalize(t *testing.T) {
+	checkString(t, RomanizeNormalize("Xiao", "pinyin"), "shiao", "should respell x")
+	checkString(t, RomanizeNormalize("Hsiao", "pinyin"), "shiao", "should fold Wade-Giles hs")
+	checkString(t, RomanizeNormalize("Zhang", "pinyin"), "jang", "should respell zh")
+	checkString(t, RomanizeNormalize("Cao", "pinyin"), "tsao", "should respell c")
+	checkString(t, RomanizeNormalize("Chen", "pinyin"), "chen", "should keep ch")
+	checkState(t, Soundex("Xiao", 4) != Soundex("Hsiao", 4), "variants should differ unnormalized")
+	checkString(t, Soundex(RomanizeNormalize("Xiao", "pinyin"), 4),
+		Soundex(RomanizeNormalize("Hsiao", "pinyin"), 4), "variants should group once normalized")
+
+	checkString(t, RomanizeNormalize("Tiba", "hepburn"), "chiba", "should respell ti")
+	checkString(t, RomanizeNormalize("Satō", "hepburn"), "sato", "should fold long vowel")
+	checkString(t, RomanizeNormalize("Satou", "hepburn"), "sato", "should fold long vowel")
+	checkString(t, RomanizeNormalize("Shimbashi", "hepburn"), "shinbashi", "should fold m before b")
+	checkString(t, RomanizeNormalize("Shuichi", "hepburn"), "shuichi", "should not split sh")
+	for _, pair := range [][]string{{"Tuda", "Tsuda"}, {"Hukuda", "Fukuda"}} {
+		checkState(t, Soundex(pair[0], 4) != Soundex(pair[1], 4), "variants should differ unnormalized")
+		checkString(t, Soundex(RomanizeNormalize(pair[0], "hepburn"), 4),
+			Soundex(RomanizeNormalize(pair[1], "hepburn"), 4), "variants should group once normalized")
+	}
+
+	checkString(t, RomanizeNormalize("Xiao", "klingon"), "Xiao", "unknown system should leave s alone")
+}
+
+func TestGermanizeForSoundex(t *testing.T) {
+	checkString(t, GermanizeForSoundex("Schmidt"), "smidt", "should respell sch")
+	checkString(t, GermanizeForSoundex("Deutsch"), "deuch", "should respell tsch")
+	checkString(t, GermanizeForSoundex("Becker"), "beker", "should respell ck")
+	checkString(t, Soundex(GermanizeForSoundex("Schmidt"), 4),
+		Soundex(GermanizeForSoundex("Shmit"), 4), "variants should collide once processed")
+}
+
+func TestConfusableFold(t *testing.T) {
+	checkString(t, ConfusableFold("IOI"), "IOI", "letters should be kept")
+	checkString(t, ConfusableFold("101"), "IOI", "digits should fold to letters")
+	checkString(t, ConfusableFold("ab5-l2"), "ABS-IZ", "should upper case the rest")
+	checkString(t, ConfusableFold("Lol"), "LOI", "only lower case l should stand for I")
+	checkString(t, ConfusableFold("B8"), ConfusableFold("8B"), "plates should compare once folded")
+}
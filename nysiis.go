@@ -0,0 +1,141 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+)
+
+
+/**
+ * Prefixes and suffixes respelled by NYSIIS before coding, in the order
+ * tried; only the first match of each list is applied.
+ */
+var (
+	nysiisPrefixes = []romanRule{
+		{"MAC", "MCC"}, {"KN", "NN"}, {"K", "C"}, {"PH", "FF"}, {"PF", "FF"}, {"SCH", "SSS"},
+	}
+	nysiisSuffixes = []romanRule{
+		{"EE", "Y"}, {"IE", "Y"}, {"DT", "D"}, {"RT", "D"}, {"RD", "D"}, {"NT", "D"}, {"ND", "D"},
+	}
+)
+
+
+/**
+ * This is the New York State Identification and Intelligence System
+ * code, by Robert L. Taft, 1970, cut to six characters as originally
+ * specified. "Macintosh" is MCANT, "Knuth" NAT.
+ */
+func NYSIIS(name string) string {
+	
+	code := nysiis(name)
+	if len(code) > 6 {
+		code = code[:6]
+	}
+	
+	return code
+}
+
+
+/**
+ * NYSIIS of name without the cut to six characters, so that long names
+ * keep their later sounds: "Phillipson" is FALAPSAN rather than FALAPS.
+ */
+func NYSIISModified(name string) string {
+	return nysiis(name)
+}
+
+
+func nysiis(name string) string {
+	
+	w := []byte(strings.ToUpper(lowerAlpha(name)))
+	if len(w) == 0 {
+		return ""
+	}
+	
+	s := string(w)
+	for _, r := range nysiisPrefixes {
+		if strings.HasPrefix(s, r.from) {
+			s = r.to + s[len(r.from):]
+			break
+		}
+	}
+	for _, r := range nysiisSuffixes {
+		if strings.HasSuffix(s, r.from) {
+			s = s[:len(s)-len(r.from)] + r.to
+			break
+		}
+	}
+	w = []byte(s)
+	
+	at := func(i int) byte {
+		if i >= len(w) {
+			return ' '
+		}
+		return w[i]
+	}
+	vowel := func(c byte) bool {
+		return byteIn(c, "AEIOU")
+	}
+	
+	code := []byte{w[0]}
+	
+	// Each step rewrites w from i on, so later steps see the result.
+	for i := 1; i < len(w); i++ {
+		prev, c, next := w[i-1], w[i], at(i+1)
+		var to string
+		switch {
+		case c == 'E' && next == 'V':
+			to = "AF"
+		case vowel(c):
+			to = "A"
+		case c == 'Q':
+			to = "G"
+		case c == 'Z':
+			to = "S"
+		case c == 'M':
+			to = "N"
+		case c == 'K' && next == 'N':
+			to = "NN"
+		case c == 'K':
+			to = "C"
+		case c == 'S' && next == 'C' && at(i+2) == 'H':
+			to = "SSS"
+		case c == 'P' && next == 'H':
+			to = "FF"
+		case c == 'H' && (!vowel(prev) || !vowel(next)):
+			to = string(prev)
+		case c == 'W' && vowel(prev):
+			to = string(prev)
+		default:
+			to = string(c)
+		}
+		copy(w[i:], []byte(to))
+		
+		if w[i] != prev {
+			code = append(code, w[i])
+		}
+	}
+	
+	// trailing transformations
+	if len(code) > 1 && code[len(code)-1] == 'S' {
+		code = code[:len(code)-1]
+	}
+	if len(code) > 2 && string(code[len(code)-2:]) == "AY" {
+		code = append(code[:len(code)-2], 'Y')
+	}
+	if len(code) > 1 && code[len(code)-1] == 'A' {
+		code = code[:len(code)-1]
+	}
+	
+	return string(code)
+}
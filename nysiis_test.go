@@ -0,0 +1,43 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+type nysiisTest struct {
+	in, out, modified string
+}
+
+var nysiisTests = []nysiisTest {
+	nysiisTest{"Macintosh", "MCANT", "MCANT"},
+	nysiisTest{"Knuth", "NAT", "NAT"},
+	nysiisTest{"Phillipson", "FALAPS", "FALAPSAN"},
+	nysiisTest{"Schmidt", "SNAD", "SNAD"},
+	nysiisTest{"Kelly", "CALY", "CALY"},
+	nysiisTest{"Evans", "EVAN", "EVAN"},
+	nysiisTest{"Brown", "BRAN", "BRAN"},
+	nysiisTest{"", "", ""},
+}
+
+func TestNYSIIS(t *testing.T) {
+	for _, dt := range nysiisTests {
+		if rv := NYSIIS(dt.in); rv != dt.out {
+			t.Errorf("NYSIIS(%s) = `%s`, want `%s`", dt.in, rv, dt.out)
+		}
+		if rv := NYSIISModified(dt.in); rv != dt.modified {
+			t.Errorf("NYSIISModified(%s) = `%s`, want `%s`", dt.in, rv, dt.modified)
+		}
+	}
+}
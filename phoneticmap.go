@@ -0,0 +1,100 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+
+/**
+ * PhoneticMap is a search index from IDs to names, looked up by the
+ * code of a name under a chosen encoder.
+ */
+type PhoneticMap struct {
+	enc     Encoder
+	names   map[string]string   // id -> name
+	codes   map[string]string   // id -> code of its name
+	buckets map[string][]string // code -> ids, in insertion order
+}
+
+
+func NewPhoneticMap(enc Encoder) *PhoneticMap {
+	return &PhoneticMap{enc, make(map[string]string), make(map[string]string), make(map[string][]string)}
+}
+
+
+/**
+ * Add id with the given name, replacing any name id already had. A
+ * name with a blank code, e.g. "007", is held but never looked up, as
+ * for BuildIndex.
+ */
+func (m *PhoneticMap) Add(id string, name string) {
+	m.Remove(id)
+	code := m.enc.Encode(name)
+	m.names[id] = name
+	m.codes[id] = code
+	if code != "" {
+		m.buckets[code] = append(m.buckets[code], id)
+	}
+}
+
+
+/**
+ * Remove id, if present.
+ */
+func (m *PhoneticMap) Remove(id string) {
+	code, ok := m.codes[id]
+	if !ok {
+		return
+	}
+	
+	bucket := make([]string, 0, len(m.buckets[code]))
+	for _, other := range m.buckets[code] {
+		if other != id {
+			bucket = append(bucket, other)
+		}
+	}
+	
+	if len(bucket) == 0 {
+		m.buckets[code] = nil, false
+	} else {
+		m.buckets[code] = bucket
+	}
+	m.names[id] = "", false
+	m.codes[id] = "", false
+}
+
+
+// Name of id, and whether id is present.
+func (m *PhoneticMap) Name(id string) (string, bool) {
+	name, ok := m.names[id]
+	return name, ok
+}
+
+
+// Number of IDs held.
+func (m *PhoneticMap) Len() int {
+	return len(m.names)
+}
+
+
+/**
+ * IDs whose names share the code of query, in the order they were
+ * added. A query with a blank code finds nothing.
+ */
+func (m *PhoneticMap) Lookup(query string) []string {
+	code := m.enc.Encode(query)
+	if code == "" {
+		return []string{}
+	}
+	bucket := m.buckets[code]
+	rv := make([]string, len(bucket))
+	copy(rv, bucket)
+	return rv
+}
@@ -0,0 +1,56 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestPhoneticMap(t *testing.T) {
+	m := NewPhoneticMap(SoundexEncoder)
+	m.Add("1", "Robert")
+	m.Add("2", "Smith")
+	m.Add("3", "Rupert")
+	m.Add("4", "Smyth")
+	checkState(t, m.Len() == 4, "should hold four ids")
+
+	checkCapture(t, []string{"1", "3"}, m.Lookup("Robbert"), "misspelled query should find both")
+	checkCapture(t, []string{"2", "4"}, m.Lookup("Smithh"), "misspelled query should find both")
+	checkCapture(t, []string{}, m.Lookup("Jones"), "unknown query should find nothing")
+
+	m.Add("3", "Jones")
+	checkCapture(t, []string{"1"}, m.Lookup("Robert"), "updated id should leave its old bucket")
+	checkCapture(t, []string{"3"}, m.Lookup("Jonas"), "updated id should join its new bucket")
+	name, ok := m.Name("3")
+	checkState(t, ok && name == "Jones", "should hold the new name")
+
+	m.Remove("1")
+	m.Remove("missing")
+	checkCapture(t, []string{}, m.Lookup("Robert"), "removed id should not be found")
+	_, ok = m.Name("1")
+	checkState(t, !ok, "removed id should be gone")
+	checkState(t, m.Len() == 3, "should hold three ids")
+}
+
+
+func TestPhoneticMapBlankCode(t *testing.T) {
+	m := NewPhoneticMap(SoundexEncoder)
+	m.Add("1", "007")
+	m.Add("2", "Robert")
+	checkCapture(t, []string{}, m.Lookup("123"), "query without letters should find nothing")
+	checkCapture(t, []string{}, m.Lookup(""), "blank query should find nothing")
+	name, ok := m.Name("1")
+	checkState(t, ok && name == "007", "should still hold a name with a blank code")
+	m.Remove("1")
+	checkState(t, m.Len() == 1, "should remove a name with a blank code")
+}
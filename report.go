@@ -0,0 +1,66 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+)
+
+
+/**
+ * Jaro-Winkler score, of the upper-cased names, at or above which
+ * Explain reports a possible match even if no code agrees.
+ */
+const explainPossibleScore = 0.85
+
+
+/**
+ * Everything behind a match decision between two names, as returned by
+ * Explain. Verdict is "match" if both the Soundex and the Metaphone
+ * codes agree, "possible" if one does or the names score at least
+ * 0.85, and "no match" otherwise.
+ */
+type MatchReport struct {
+	A, B                   string
+	SoundexA, SoundexB     string
+	MetaphoneA, MetaphoneB string
+	JaroWinkler            float64
+	Verdict                string
+}
+
+
+/**
+ * Compare names a and b for an audit log, encoding each with Soundex
+ * and Metaphone and scoring their upper-cased spellings with
+ * JaroWinkler.
+ */
+func Explain(a, b string) MatchReport {
+	
+	r := MatchReport{A: a, B: b}
+	r.SoundexA, r.SoundexB = SoundexEncoder.Encode(a), SoundexEncoder.Encode(b)
+	r.MetaphoneA, r.MetaphoneB = MetaphoneEncoder.Encode(a), MetaphoneEncoder.Encode(b)
+	r.JaroWinkler = JaroWinkler(strings.ToUpper(a), strings.ToUpper(b))
+	
+	soundex := r.SoundexA != "" && r.SoundexA == r.SoundexB
+	metaphone := r.MetaphoneA != "" && r.MetaphoneA == r.MetaphoneB
+	
+	switch {
+	case soundex && metaphone:
+		r.Verdict = "match"
+	case soundex || metaphone || r.JaroWinkler >= explainPossibleScore:
+		r.Verdict = "possible"
+	default:
+		r.Verdict = "no match"
+	}
+	
+	return r
+}
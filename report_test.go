@@ -0,0 +1,30 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestExplain(t *testing.T) {
+	r := Explain("Robert", "Rupert")
+	checkString(t, r.A+","+r.B, "Robert,Rupert", "should keep the names")
+	checkString(t, r.SoundexA+","+r.SoundexB, "R163,R163", "should encode with Soundex")
+	checkString(t, r.MetaphoneA+","+r.MetaphoneB, "RBRT,RPRT", "should encode with Metaphone")
+	checkState(t, r.JaroWinkler > 0.799 && r.JaroWinkler < 0.801, "should score 0.8")
+	checkString(t, r.Verdict, "possible", "only Soundex should agree")
+
+	checkString(t, Explain("Smith", "smith").Verdict, "match", "should ignore case")
+	checkString(t, Explain("Robert", "Lee").Verdict, "no match", "nothing should agree")
+	checkString(t, Explain("Jon", "John").Verdict, "match", "spelling variants should match")
+}
@@ -12,26 +12,182 @@ package phonetic
 
 import (
 	"strings"
+	"unicode"
 )
 
 
 
 var digits string = "01230120022455012623010202"
 
+// As digits, but vowels are coded by where they're made: A is 7, the
+// front vowels E, I and Y are 8, and the back vowels O and U are 9.
+var vowelDigits string = "71238120822455912623910282"
+
+// Digits of the refined Soundex, splitting the classic groups: B and P
+// are 1, F and V 2, C, K and S 3, G and J 4, Q, X and Z 5, D and T 6,
+// L 7, M and N 8, R 9, and vowels, H, W and Y 0.
+var refinedDigits string = "01360240043788015936020505"
+
 func isAlpha(ch int) bool {
-	return ch <= 'z' && ch >= 'A'
+	return (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z')
 }
 
 
+// SoundexKind selects one of the Soundex conventions.
+type SoundexKind int
+
+const (
+	SoundexSimplified SoundexKind = iota // H and W separate like vowels
+	SoundexAmerican                      // NARA rules, H and W are skipped
+)
+
+
+/**
+ * Soundex of name padded or cut to length, using the simplified
+ * convention: H and W separate same-coded letters just as vowels do,
+ * so "Ashcraft" is A226. For the American rule, which gives A261, use
+ * SoundexVariant with SoundexAmerican. Anything but letters, digits
+ * included, is skipped: "Route66" codes as "Route". See
+ * SoundexKeepDigits to keep them.
+ */
 func Soundex(name string, length int) string {
+	return SoundexVariant(name, length, SoundexSimplified)
+}
+
+
+/**
+ * Soundex of name following the given convention. The two differ only
+ * where H or W sits between same-coded letters: "Ashcraft" is A226
+ * simplified, but A261 American.
+ */
+func SoundexVariant(name string, length int, variant SoundexKind) string {
+	return soundexWith(name, length, digits, variant)
+}
+
+
+/**
+ * Soundex-like code of name which keeps vowels as digits 7 to 9 (see
+ * vowelDigits) rather than dropping them, so names differing only by
+ * their vowels get different codes. H and W still separate like vowels.
+ */
+func VowelSoundex(name string, length int) string {
+	return soundexWith(name, length, vowelDigits, SoundexSimplified)
+}
+
+
+/**
+ * Soundex-like code of name giving the first letter followed by its own
+ * digit, then the rest of the code as usual: "Robert" is R6163 where
+ * Soundex gives R163. Past the letter, the key codes the whole name by
+ * sound, so Cole and Kole agree from the second character on, while the
+ * letter keeps it readable. Vowel initials get the digit 0. The length
+ * includes the letter.
+ */
+func SoundexPrefixed(name string, length int) string {
+	
+	fc, sndx := soundexDigits(name, digits, SoundexSimplified)
+	
+	if len(sndx) == 0 {
+		return ""
+	}
+	
+	return padSoundex(string(fc) + sndx[:1] + strings.Replace(sndx[1:], "0", "", -1), length)
+}
+
+
+/**
+ * Soundex of the letters of name, as for Soundex, followed by every
+ * digit of name in order, for alphanumeric codes: "Route66" is R30066
+ * where Soundex gives R300. A name of digits alone keeps just those.
+ */
+func SoundexKeepDigits(name string, length int) string {
+	
+	kept := ""
+	
+	for _, c := range name {
+		if c >= '0' && c <= '9' {
+			kept += string(c)
+		}
+	}
+	
+	return Soundex(name, length) + kept
+}
+
+
+/**
+ * Refined Soundex of name: the first letter, then the digit of every
+ * letter including the first, runs of the same digit collapsed. Vowels
+ * are kept as 0 and the code is neither padded nor cut, so it tells
+ * apart names the classic code joins: Braz is B1905 and Bras B1903,
+ * where Soundex gives both B620. The table is the standard one of
+ * Apache Commons Codec, kept on purpose over a coarser C/G/J/K/Q and
+ * S/X/Z grouping so codes agree with other implementations.
+ */
+func RefinedSoundex(name string) string {
+	
+	fc, sndx := soundexDigits(name, refinedDigits, SoundexSimplified)
+	
+	if len(sndx) == 0 {
+		return ""
+	}
+	
+	return string(fc) + sndx
+}
+
+
+/**
+ * Soundex of each word of phrase, in order, padded or cut to length.
+ * Words are split at white space and punctuation, so "Jean-Luc Picard"
+ * gives J500, L200 and P263, but an apostrophe is kept within a word,
+ * so O'Brien is one. Words giving a blank code, such as "42", are
+ * dropped.
+ */
+func SoundexWords(phrase string, length int) []string {
+	
+	words := strings.FieldsFunc(phrase, func(c int) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '\''
+	})
+	
+	codes := make([]string, 0, len(words))
+	
+	for _, word := range words {
+		if code := Soundex(word, length); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	
+	return codes
+}
+
+
+func soundexWith(name string, length int, digits string, variant SoundexKind) string {
+	
+	fc, sndx := soundexDigits(name, digits, variant)
+	
+	if len(sndx) == 0 {
+		return ""
+	}
+	
+	sndx = string(fc) + sndx[1:]
+	
+	sndx = strings.Replace(sndx, "0", "", -1)
 	
-	sndx := ""
-	var fc int = 0
+	return padSoundex(sndx, length)
+}
+
+
+/**
+ * The first letter of name, and the digits of all its letters with
+ * runs of the same digit collapsed, including the first letter's.
+ */
+func soundexDigits(name string, digits string, variant SoundexKind) (fc int, sndx string) {
 	
 	for _, c := range strings.ToUpper(name) {
 		if isAlpha(c) {
 			if fc == 0 {
 				fc = c
+			} else if variant == SoundexAmerican && (c == 'H' || c == 'W') {
+				continue
 			}
 			d := digits[c - 'A']
 			if sndx == "" || (d != sndx[len(sndx)-1]) {
@@ -40,13 +196,11 @@ func Soundex(name string, length int) string {
 		}
 	}
 	
-	if len(sndx) == 0 {
-		return ""
-	}
-	
-	sndx = string(fc) + sndx[1:]
-	
-	sndx = strings.Replace(sndx, "0", "", -1)
+	return fc, sndx
+}
+
+
+func padSoundex(sndx string, length int) string {
 	
 	zeros := ""
 	
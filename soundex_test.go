@@ -34,3 +34,82 @@ func TestSoundex(t *testing.T) {
 }
 
 
+
+type soundexVariantTest struct {
+	in, simplified, american string
+}
+
+var soundexVariantTests = []soundexVariantTest {
+	soundexVariantTest{"Ashcraft", "A226", "A261"}, // American: H doesn't separate S and C
+	soundexVariantTest{"Ashcroft", "A226", "A261"},
+	soundexVariantTest{"Pfister", "P236", "P236"},  // F is dropped after P
+	soundexVariantTest{"Tymczak", "T522", "T522"},  // a vowel separates C and K
+	soundexVariantTest{"Robert", "R163", "R163"},
+}
+
+func TestSoundexVariant(t *testing.T) {
+	for _, dt := range soundexVariantTests {
+		rv := SoundexVariant(dt.in, 4, SoundexSimplified)
+		if rv != dt.simplified {
+			t.Errorf("SoundexVariant(%s, Simplified) = `%s`, want `%s`", dt.in, rv, dt.simplified)
+		}
+		rv = SoundexVariant(dt.in, 4, SoundexAmerican)
+		if rv != dt.american {
+			t.Errorf("SoundexVariant(%s, American) = `%s`, want `%s`", dt.in, rv, dt.american)
+		}
+		if Soundex(dt.in, 4) != dt.simplified {
+			t.Errorf("Soundex(%s) should use the simplified convention", dt.in)
+		}
+	}
+}
+
+func TestVowelSoundex(t *testing.T) {
+	checkString(t, VowelSoundex("Robin", 6), "R91850", "should code vowels")
+	checkString(t, VowelSoundex("Rabin", 6), "R71850", "should code vowels")
+	checkString(t, Soundex("Robin", 6), Soundex("Rabin", 6), "should match under standard soundex")
+	checkString(t, VowelSoundex("Lee", 4), "L800", "should collapse repeated vowels")
+	checkString(t, VowelSoundex("Ashcraft", 4), "A226", "H should still separate")
+}
+
+func TestSoundexPrefixed(t *testing.T) {
+	checkString(t, SoundexPrefixed("Robert", 5), "R6163", "should keep the initial's digit")
+	checkString(t, SoundexPrefixed("Lee", 5), "L4000", "should pad with zeros")
+	checkString(t, SoundexPrefixed("Ashcraft", 5), "A0226", "vowel initial should give 0")
+	checkString(t, SoundexPrefixed("Cole", 5)[1:], SoundexPrefixed("Kole", 5)[1:], "digits should agree")
+	checkString(t, SoundexPrefixed("", 5), "", "blank name should give blank code")
+}
+
+func TestSoundexDigits(t *testing.T) {
+	checkString(t, Soundex("Route66", 4), "R300", "should drop digits")
+	checkString(t, Soundex("Route66", 4), Soundex("Route", 4), "should code as the letters alone")
+	checkString(t, Soundex("66", 4), "", "digits alone should give blank code")
+
+	checkString(t, SoundexKeepDigits("Route66", 4), "R30066", "should append the digits")
+	checkString(t, SoundexKeepDigits("A1B2", 4), "A10012", "should keep digits in order")
+	checkString(t, SoundexKeepDigits("66", 4), "66", "digits alone should be kept")
+	checkString(t, SoundexKeepDigits("Route", 4), "R300", "should be Soundex without digits")
+}
+
+func TestRefinedSoundex(t *testing.T) {
+	checkString(t, RefinedSoundex("Braz"), "B1905", "should code Braz")
+	checkString(t, RefinedSoundex("Caren"), "C30908", "should code Caren")
+	checkString(t, RefinedSoundex("Hayers"), "H093", "should code Hayers")
+	checkString(t, RefinedSoundex(""), "", "blank name should give blank code")
+
+	checkString(t, Soundex("Braz", 4), Soundex("Bras", 4), "Soundex should join S and Z")
+	checkState(t, RefinedSoundex("Braz") != RefinedSoundex("Bras"), "should tell S from Z")
+}
+
+func TestSoundexPunctuation(t *testing.T) {
+	checkString(t, Soundex("O'Brien", 4), "O165", "should skip the apostrophe")
+	checkString(t, Soundex("O'Brien", 4), Soundex("OBrien", 4), "should code as the letters alone")
+	checkString(t, Soundex("de_la_Cruz", 4), "D426", "should skip underscores")
+	checkString(t, Soundex("[Smith]^", 4), "S530", "should skip the ASCII between Z and a")
+}
+
+func TestSoundexWords(t *testing.T) {
+	checkCapture(t, []string{"J500", "L200", "P263"}, SoundexWords("Jean-Luc Picard", 4), "should code each word")
+	checkCapture(t, []string{"M600", "J500"}, SoundexWords("  Mary\tJane ", 4), "should split at any white space")
+	checkCapture(t, []string{"O165", "K000"}, SoundexWords("O'Brien, 42 Kay", 4), "should keep apostrophes and drop blank codes")
+	checkCapture(t, []string{}, SoundexWords("", 4), "blank phrase should give no codes")
+}
@@ -0,0 +1,445 @@
+package sre2
+
+// Static analysis over compiled regexps. These work on the instruction graph
+// alone, without running the regexp against any input.
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Find the instruction at which the user's regexp begins, i.e. directly after
+// the capture which opens the outermost group. This skips the ".*?" prefix
+// which Parse places before every regexp.
+func (r *sregexp) userStart() *instr {
+	for _, i := range r.prog {
+		if i.mode == iIndexCap && i.cid == 0 {
+			return i.out
+		}
+	}
+	panic("regexp has no outer capture")
+}
+
+// Collect the rune filters which may consume the first rune of a match. If the
+// regexp may also match without consuming any rune, then empty is true.
+// Boundary instructions are treated as always passing.
+func (r *sregexp) firstFilters() (filters []RuneFilter, empty bool) {
+	seen := make(map[int]bool)
+	var walk func(i *instr)
+	walk = func(i *instr) {
+		if i == nil || seen[i.idx] {
+			return
+		}
+		seen[i.idx] = true
+		switch i.mode {
+		case iSplit:
+			walk(i.out)
+			walk(i.out1)
+		case iIndexCap:
+			if i.cid == 1 {
+				// Reached the end of the outermost group.
+				empty = true
+				return
+			}
+			walk(i.out)
+		case iBoundaryCase:
+			walk(i.out)
+		case iRuneClass:
+			filters = append(filters, i.rune)
+		case iMatch:
+			empty = true
+		}
+	}
+	walk(r.userStart())
+	return filters, empty
+}
+
+// The most runes FirstRunes lists, before reporting any instead.
+const firstRunesMax = 256
+
+// FirstRunes returns the runes, in ascending order, which may begin a match of
+// this regexp. A scanner can skip positions not starting with one of these. If
+// the regexp may match empty, or could begin with more than firstRunesMax runes
+// (e.g. due to '.'), then runes is nil and any is true. The result is computed
+// once, on the first call, and shared by later calls.
+func (r *sregexp) FirstRunes() (runes []int, any bool) {
+	r.first_once.Do(func() {
+		r.first_runes, r.first_any = r.firstRunes()
+	})
+	return r.first_runes, r.first_any
+}
+
+// Probe every rune against the filters which may begin a match, for FirstRunes.
+func (r *sregexp) firstRunes() (runes []int, any bool) {
+	filters, empty := r.firstFilters()
+	if empty {
+		return nil, true
+	}
+	runes = make([]int, 0)
+	for rune := 0; rune <= unicode.MaxRune; rune++ {
+		for _, f := range filters {
+			if f(rune) {
+				if len(runes) == firstRunesMax {
+					return nil, true
+				}
+				runes = append(runes, rune)
+				break
+			}
+		}
+	}
+	return runes, false
+}
+
+// Conflict describes two patterns which may both match at the same position.
+type Conflict struct {
+	A, B int // indexes of the conflicting patterns
+	Rune int // a rune both may begin a match with, or -1 if either may match empty
+}
+
+// Runes probed by CheckDisjoint: the Basic Multilingual Plane.
+const disjointProbeMax = 0xffff
+
+// CheckDisjoint reports pairs of patterns which may both match starting at the
+// same position, e.g. two ambiguous lexer rules. This is a heuristic: it only
+// compares the runes each pattern may begin with (probing the BMP), and treats
+// a pattern which may match empty as conflicting with every other.
+// Returns an error if any pattern was not built by this package.
+func CheckDisjoint(patterns []Re) ([]Conflict, os.Error) {
+	firsts := make([][]RuneFilter, len(patterns))
+	empties := make([]bool, len(patterns))
+	for i, re := range patterns {
+		s, ok := re.(*sregexp)
+		if !ok {
+			return nil, fmt.Errorf("pattern %d is not an sre2 regexp", i)
+		}
+		firsts[i], empties[i] = s.firstFilters()
+	}
+
+	conflicts := make([]Conflict, 0)
+	for a := 0; a < len(patterns); a++ {
+		for b := a + 1; b < len(patterns); b++ {
+			if empties[a] || empties[b] {
+				conflicts = append(conflicts, Conflict{a, b, -1})
+				continue
+			}
+			if rune := firstOverlap(firsts[a], firsts[b]); rune != -1 {
+				conflicts = append(conflicts, Conflict{a, b, rune})
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// Find the lowest rune matched by some filter in both a and b, or -1 if none.
+func firstOverlap(a []RuneFilter, b []RuneFilter) int {
+	if len(a) == 0 || len(b) == 0 {
+		return -1
+	}
+	matches := func(filters []RuneFilter, rune int) bool {
+		for _, f := range filters {
+			if f(rune) {
+				return true
+			}
+		}
+		return false
+	}
+	for rune := 0; rune <= disjointProbeMax; rune++ {
+		if matches(a, rune) && matches(b, rune) {
+			return rune
+		}
+	}
+	return -1
+}
+
+// The most configurations IsSubset explores before giving up.
+const subsetMaxStates = 10000
+
+// IsSubset reports whether every string matched by this regexp is also matched
+// by other. This is conservative: it explores both regexps side by side over
+// one rune of each class they treat alike, probing the BMP as CheckDisjoint
+// does, and returns false once that takes more than subsetMaxStates steps, or
+// if other was not built by this package. Runes beyond the BMP are ignored.
+func (r *sregexp) IsSubset(other Re) bool {
+	o, ok := other.(*sregexp)
+	if !ok {
+		return false
+	}
+
+	// Each configuration holds the instrs each regexp will proceed from, and
+	// the rune last consumed (for boundaries).
+	type config struct {
+		a, b []int
+		last int
+	}
+	symbols := append(probeClasses(r, o), -1) // -1 ends the input
+	seen := make(map[string]bool)
+	queue := []config{{[]int{r.start}, []int{o.start}, -1}}
+
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		key := fmt.Sprint(c.a, c.b, c.last)
+		if seen[key] {
+			continue
+		}
+		if len(seen) == subsetMaxStates {
+			return false // too complex, so unsure
+		}
+		seen[key] = true
+
+		for _, next := range symbols {
+			states_a, match_a := r.closure(c.a, c.last, next)
+			states_b, match_b := o.closure(c.b, c.last, next)
+			if next == -1 {
+				if match_a && !match_b {
+					return false // found a string matched only by this regexp
+				}
+				continue
+			}
+			a := r.step(states_a, next)
+			if len(a) == 0 {
+				continue // this regexp can't match past here
+			}
+			queue = append(queue, config{a, o.step(states_b, next), next})
+		}
+	}
+	return true
+}
+
+// Find one rune of each class of runes in the BMP which the given regexps can't
+// tell apart: alike runes are matched by the same rune class instrs, and look
+// the same to boundaries.
+func probeClasses(regexps ...*sregexp) []int {
+	filters := make([]RuneFilter, 0)
+	for _, r := range regexps {
+		for _, i := range r.prog {
+			if i.mode == iRuneClass {
+				filters = append(filters, i.rune)
+			}
+		}
+	}
+
+	classes := make(map[string]bool)
+	reps := make([]int, 0)
+	sig := make([]byte, len(filters)+3)
+	bit := func(b bool) byte {
+		if b {
+			return '1'
+		}
+		return '0'
+	}
+	for rune := 0; rune <= disjointProbeMax; rune++ {
+		for i, f := range filters {
+			sig[i] = bit(f(rune))
+		}
+		sig[len(filters)] = bit(isWordRune(rune))
+		sig[len(filters)+1] = bit(unicode.Is(perl_groups['s'], rune))
+		sig[len(filters)+2] = bit(rune == '\n')
+		if key := string(sig); !classes[key] {
+			classes[key] = true
+			reps = append(reps, rune)
+		}
+	}
+	return reps
+}
+
+// Follow the given instrs through splits, captures and boundaries (between the
+// runes left and right), as stateList.addstate does. Returns the sorted rune
+// class instrs reached, and whether the match instr was.
+func (r *sregexp) closure(from []int, left int, right int) (states []int, match bool) {
+	seen := make(map[int]bool)
+	var walk func(i *instr)
+	walk = func(i *instr) {
+		if i == nil || seen[i.idx] {
+			return
+		}
+		seen[i.idx] = true
+		switch i.mode {
+		case iSplit:
+			walk(i.out)
+			walk(i.out1)
+		case iIndexCap:
+			walk(i.out)
+		case iBoundaryCase:
+			if i.matchBoundaryMode(left, right) {
+				walk(i.out)
+			}
+		case iRuneClass:
+			states = append(states, i.idx)
+		case iMatch:
+			match = true
+		}
+	}
+	for _, idx := range from {
+		walk(r.prog[idx])
+	}
+	sort.Ints(states)
+	return states, match
+}
+
+// Consume rune from the given rune class instrs, returning the sorted instrs to
+// proceed from.
+func (r *sregexp) step(states []int, rune int) []int {
+	seen := make(map[int]bool)
+	next := make([]int, 0)
+	for _, idx := range states {
+		i := r.prog[idx]
+		if i.match(rune) && !seen[i.out.idx] {
+			seen[i.out.idx] = true
+			next = append(next, i.out.idx)
+		}
+	}
+	sort.Ints(next)
+	return next
+}
+
+// Describe a boundary mode in words, for Explain.
+func describeBoundary(lr boundaryMode) string {
+	switch lr {
+	case bBeginText:
+		return "anchored at start of text"
+	case bBeginLine:
+		return "anchored at start of line"
+	case bEndText:
+		return "anchored at end of text"
+	case bEndLine:
+		return "anchored at end of line"
+	case bWordBoundary:
+		return "a word boundary"
+	case bNotWordBoundary:
+		return "not a word boundary"
+	case bBeginWord:
+		return "the start of a word"
+	case bEndWord:
+		return "the end of a word"
+	}
+	return "an unknown boundary"
+}
+
+// Describe a rune class instr in words, for Explain.
+func describeRune(i *instr) string {
+	if len(i.desc) != 0 {
+		return i.desc
+	}
+	return "a rune"
+}
+
+// Explain describes this regexp in plain English, e.g. "anchored at start of
+// text, then a digit, one or more times, then anchored at end of text". This
+// walks the instruction graph in order, recognising the shapes which '*', '+'
+// and '?' compile to around a single rune class. Anything more complex, such
+// as alternation, is summarised rather than described in full.
+func (r *sregexp) Explain() string {
+	parts := make([]string, 0)
+	seen := make(map[int]bool)
+	i := r.userStart()
+outer:
+	for i != nil && !seen[i.idx] {
+		seen[i.idx] = true
+		switch i.mode {
+		case iIndexCap:
+			if i.cid == 1 {
+				break outer // end of the outermost group
+			}
+			if i.cid%2 == 0 {
+				parts = append(parts, fmt.Sprintf("start of group %d", i.cid/2))
+			} else {
+				parts = append(parts, fmt.Sprintf("end of group %d", i.cid/2))
+			}
+			i = i.out
+		case iBoundaryCase:
+			parts = append(parts, describeBoundary(i.lr))
+			i = i.out
+		case iRuneClass:
+			// A following split which loops back here is '+'.
+			if s := i.out; s != nil && s.mode == iSplit && (s.out == i || s.out1 == i) {
+				parts = append(parts, describeRune(i)+", one or more times")
+				seen[s.idx] = true
+				if s.out == i {
+					i = s.out1
+				} else {
+					i = s.out
+				}
+				continue
+			}
+			parts = append(parts, describeRune(i))
+			i = i.out
+		case iSplit:
+			if i.out1 == nil {
+				i = i.out
+				continue
+			}
+			// A rune class branch which loops back to this split is '*'; one which
+			// rejoins the other branch is '?'.
+			explained := false
+			for _, b := range [][2]*instr{[2]*instr{i.out, i.out1}, [2]*instr{i.out1, i.out}} {
+				branch, other := b[0], b[1]
+				if branch.mode != iRuneClass {
+					continue
+				}
+				if branch.out == i {
+					parts = append(parts, describeRune(branch)+", zero or more times")
+				} else if branch.out == other {
+					parts = append(parts, describeRune(branch)+", optionally")
+				} else {
+					continue
+				}
+				seen[branch.idx] = true
+				i = other
+				explained = true
+				break
+			}
+			if !explained {
+				parts = append(parts, "one of several alternatives")
+				break outer
+			}
+		case iMatch:
+			break outer
+		}
+	}
+	if len(parts) == 0 {
+		return "the empty string"
+	}
+	return strings.Join(parts, ", then ")
+}
+
+// Instruction is a read-only copy of one instruction of a compiled regexp, as
+// returned by Program. Modes are named as in DebugOut, e.g. "iSplit".
+type Instruction struct {
+	Index     int
+	Mode      string // "iSplit", "iIndexCap", "iBoundaryCase", "iRuneClass" or "iMatch"
+	Out, Out1 int    // indexes of the next instructions, or -1 for none
+	Cid       int    // capture id, for iIndexCap: 2n opens group n, 2n+1 closes it
+	Cname     string // capture name, for iIndexCap (blank=none)
+	Boundary  string // boundary mode, for iBoundaryCase, e.g. "bEndText"
+	Rune      string // description of the rune class, for iRuneClass
+}
+
+// Program returns a copy of the compiled instructions of this regexp, in order,
+// including the ".*?( ... ).*?" which Parse wraps around every regexp. Changes
+// to the result do not affect the regexp.
+func (r *sregexp) Program() []Instruction {
+	prog := make([]Instruction, len(r.prog))
+	for idx, i := range r.prog {
+		p := Instruction{Index: i.idx, Mode: i.mode.String(), Out: -1, Out1: -1, Cid: -1}
+		if i.out != nil {
+			p.Out = i.out.idx
+		}
+		if i.out1 != nil {
+			p.Out1 = i.out1.idx
+		}
+		switch i.mode {
+		case iIndexCap:
+			p.Cid, p.Cname = i.cid, i.cname
+		case iBoundaryCase:
+			p.Boundary = i.lr.String()
+		case iRuneClass:
+			p.Rune = describeRune(i)
+		}
+		prog[idx] = p
+	}
+	return prog
+}
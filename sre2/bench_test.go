@@ -50,6 +50,20 @@ func BenchmarkMatchClass(b *testing.B) {
 	}
 }
 
+func BenchmarkMatchClass_Merged(b *testing.B) {
+	b.StopTimer()
+	// The literal runes here merge into the single range a-h.
+	x := strings.Repeat("xxxx", 20) + "h"
+	re := MustParse("[hgfedcba]")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		if !re.Match(x) {
+			println("no match!")
+			break
+		}
+	}
+}
+
 func BenchmarkMatchClass_InRange(b *testing.B) {
 	b.StopTimer()
 	// 'b' is between 'a' and 'c', so the charclass
@@ -64,17 +78,17 @@ func BenchmarkMatchClass_InRange(b *testing.B) {
 		}
 	}
 }
-/*
+
 func BenchmarkReplaceAll(b *testing.B) {
 	x := "abcdefghijklmnopqrstuvwxyz"
 	b.StopTimer()
 	re := MustParse("[cjrw]")
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		re.ReplaceAllString(x, "")
+		re.ReplaceAll(x, "")
 	}
 }
-*/
+
 func BenchmarkAnchoredLiteralShortNonMatch(b *testing.B) {
 	b.StopTimer()
 	x := "abcdefghijklmnopqrstuvwxyz"
@@ -123,3 +137,52 @@ func BenchmarkComplexRe(b *testing.B) {
 		re.Match("aba#hello")
 	}
 }
+
+func BenchmarkExtract(b *testing.B) {
+	b.StopTimer()
+	re := MustParse("(\\w+)@(\\w+)\\.com")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		re.Extract("mail bob@example.com", 2)
+	}
+}
+
+func BenchmarkExtractInto(b *testing.B) {
+	b.StopTimer()
+	re := MustParse("(\\w+)@(\\w+)\\.com")
+	buf := make([]string, 0, 3)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		buf = re.ExtractInto("mail bob@example.com", buf[:0])
+	}
+}
+
+func batchInputs() []string {
+	inputs := make([]string, 1000)
+	for i := range inputs {
+		inputs[i] = strings.Repeat("a", i%7) + "b"
+	}
+	return inputs
+}
+
+func BenchmarkMatchSerial(b *testing.B) {
+	b.StopTimer()
+	re := MustParse("^a+b$")
+	inputs := batchInputs()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for _, src := range inputs {
+			re.Match(src)
+		}
+	}
+}
+
+func BenchmarkMatchBatch(b *testing.B) {
+	b.StopTimer()
+	re := MustParse("^a+b$")
+	inputs := batchInputs()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		re.MatchBatch(inputs)
+	}
+}
@@ -2,32 +2,72 @@ package sre2
 
 import (
 	"container/vector"
+	"sort"
 	"unicode"
 )
 
 // RuneFilter is a unique method signature for matching true/false over a given
-// unicode rune.
+// unicode rune. Filters may be built and combined with the constructors and
+// methods below, as the parser does for each character class.
 type RuneFilter func(rune int) bool
 
-// Generate a RuneFilter matching a single rune.
-func matchRune(to_match int) RuneFilter {
+// MatchRune generates a RuneFilter matching a single rune.
+func MatchRune(to_match int) RuneFilter {
 	return func(rune int) bool {
 		return rune == to_match
 	}
 }
 
-// Generate a RuneFilter matching a range of runes, assumes from <= to.
-func matchRuneRange(from int, to int) RuneFilter {
+// MatchRuneRange generates a RuneFilter matching a range of runes, assumes
+// from <= to.
+func MatchRuneRange(from int, to int) RuneFilter {
 	return func(rune int) bool {
 		return rune >= from && rune <= to
 	}
 }
 
-// Generate a RuneFilter matching a valid Unicode class. If no matching classes
+// runeRange is an inclusive range of runes, from lo to hi.
+type runeRange struct {
+	lo, hi int
+}
+
+// runeRanges implements sort.Interface, ordering by the start of each range.
+type runeRanges []runeRange
+
+func (r runeRanges) Len() int           { return len(r) }
+func (r runeRanges) Less(i, j int) bool { return r[i].lo < r[j].lo }
+func (r runeRanges) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
+// Merge the given ranges into the smallest set of ranges covering the same
+// runes, ordered by start. Adjacent and overlapping ranges are joined.
+func mergeRanges(ranges []runeRange) []runeRange {
+	if len(ranges) == 0 {
+		return ranges
+	}
+	sorted := make(runeRanges, len(ranges))
+	copy(sorted, ranges)
+	sort.Sort(sorted)
+
+	merged := []runeRange{sorted[0]}
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if r.lo <= last.hi+1 {
+			if r.hi > last.hi {
+				last.hi = r.hi
+			}
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	return merged
+}
+
+// MatchUnicodeClass generates a RuneFilter matching a valid Unicode class,
+// such as "Greek" or "Lu", as for \p{...}. If no matching classes
 // are found, then this method will return nil.
 // Note that if just a single character is given, Categories will be searched
 // for this as a prefix (so that 'N' will match 'Nd', 'Nl', 'No' etc).
-func matchUnicodeClass(class string) RuneFilter {
+func MatchUnicodeClass(class string) RuneFilter {
 	found := false
 	var match vector.Vector
 	if len(class) == 1 {
@@ -64,15 +104,16 @@ func matchUnicodeClass(class string) RuneFilter {
 	return nil
 }
 
-// Generate and return a new, inverse RuneFilter from the argument.
-func (r RuneFilter) not() RuneFilter {
+// Not generates and returns a new, inverse RuneFilter from the receiver.
+func (r RuneFilter) Not() RuneFilter {
 	return func(rune int) bool {
 		return !r(rune)
 	}
 }
 
-// Generate and return a new RuneFilter, which ignores case, from the argument.
-func (r RuneFilter) ignoreCase() RuneFilter {
+// IgnoreCase generates and returns a new RuneFilter, which ignores case, from
+// the receiver.
+func (r RuneFilter) IgnoreCase() RuneFilter {
 	return func(rune int) bool {
 		return r(unicode.ToLower(rune)) || r(unicode.ToUpper(rune))
 	}
@@ -19,6 +19,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 )
 
@@ -31,6 +32,14 @@ type sregexp struct {
 	// Number of paired subexpressions [()'s], including the outermost brackets
 	// (i.e. which match the entire string).
 	caps int
+
+	// Flags in effect at the end of the top level, as parser.flags.
+	flags int64
+
+	// Result of FirstRunes, computed on its first call.
+	first_once  sync.Once
+	first_runes []int
+	first_any   bool
 }
 
 // DebugOut writes the given regexp to Stderr, for debugging.
@@ -40,12 +49,38 @@ func (r *sregexp) DebugOut() {
 	}
 }
 
+// Flags returns the flags in effect at the top level of this regexp, as their
+// characters in ascending order, e.g. "im" for "(?mi)abc" or "(?i)a(?m)b".
+// Flags set within a group, or cleared again by the end of the regexp, are
+// not included; one implied by ParseWithDotMode is.
+func (r *sregexp) Flags() string {
+	flags := ""
+	for flag := 64; flag < 128; flag++ {
+		if r.flags&(1<<byte(flag-64)) != 0 {
+			flags += string(flag)
+		}
+	}
+	return flags
+}
+
 // NumSubexps returns the number of paired subexpressions [()'s] in this regexp.
 func (r *sregexp) NumSubexps() int {
 	// we always have an outer () to match the whole re, subtract it
 	return r.caps - 1
 }
 
+// SubexpNames returns the name given by (?P<name>...) to each group, indexed
+// by group number, with "" for unnamed groups and for group 0, the whole match.
+func (r *sregexp) SubexpNames() []string {
+	names := make([]string, r.caps)
+	for _, i := range r.prog {
+		if i.mode == iIndexCap && len(i.cname) != 0 {
+			names[i.cid/2] = i.cname
+		}
+	}
+	return names
+}
+
 // instrMode describes a particular instruction type for the regexp internal
 // state machine.
 type instrMode byte
@@ -72,8 +107,25 @@ const (
 	bEndLine                      // end of text or line
 	bWordBoundary                 // ascii word boundary
 	bNotWordBoundary              // inverse of above, not ascii word boundary
+	bBeginWord                    // ascii start of word, e.g. \<
+	bEndWord                      // ascii end of word, e.g. \>
 )
 
+// Names of each instrMode, as they appear in the code.
+var instrModeNames = []string{"iSplit", "iIndexCap", "iBoundaryCase", "iRuneClass", "iMatch"}
+
+func (m instrMode) String() string {
+	return instrModeNames[m]
+}
+
+// Names of each boundaryMode, as they appear in the code. bNone is blank.
+var boundaryModeNames = []string{"", "bBeginText", "bBeginLine", "bEndText", "bEndLine",
+	"bWordBoundary", "bNotWordBoundary", "bBeginWord", "bEndWord"}
+
+func (lr boundaryMode) String() string {
+	return boundaryModeNames[lr]
+}
+
 // instr represents a single instruction in any regexp.
 type instr struct {
 	idx  int       // index of this instr
@@ -88,6 +140,7 @@ type instr struct {
 
 	// rune class to match against, for iRuneClass
 	rune RuneFilter
+	desc string // human-readable form of rune, for Explain (blank=unknown)
 
 	// identifier of submatch for iIndexCap
 	cid   int    // numbered index
@@ -114,22 +167,7 @@ func (i *instr) String() string {
 			str += fmt.Sprintf(" cname=%s", i.cname)
 		}
 	case iBoundaryCase:
-		var mode string
-		switch i.lr {
-		case bBeginText:
-			mode = "bBeginText"
-		case bBeginLine:
-			mode = "bBeginLine"
-		case bEndText:
-			mode = "bEndText"
-		case bEndLine:
-			mode = "bEndLine"
-		case bWordBoundary:
-			mode = "bWordBoundary"
-		case bNotWordBoundary:
-			mode = "bNotWordBoundary"
-		}
-		str += fmt.Sprintf(" iBoundaryCase [%s]", mode)
+		str += fmt.Sprintf(" iBoundaryCase [%s]", i.lr)
 	case iRuneClass:
 		str += fmt.Sprint(" iRuneClass ", i.rune)
 	case iMatch:
@@ -168,10 +206,20 @@ func (s *instr) matchBoundaryMode(left int, right int) bool {
 		} else {
 			return !wb
 		}
+	case bBeginWord:
+		return !isWordRune(left) && isWordRune(right)
+	case bEndWord:
+		return isWordRune(left) && !isWordRune(right)
 	}
 	panic("unexpected lr mode")
 }
 
+// Determine whether the given rune is an ASCII word character. The rune -1,
+// representing a position outside the target string, is never a word rune.
+func isWordRune(rune int) bool {
+	return rune != -1 && unicode.Is(perl_groups['w'], rune)
+}
+
 // Escape constants and their mapping to actual Unicode runes.
 var (
 	ESCAPES = map[int]int{
@@ -184,6 +232,9 @@ type parser struct {
 	re    *sregexp
 	src   SafeReader
 	flags int64 // on/off state for flags 64-127 (subtract 64, uses bits)
+
+	// if set, '.' never matches '\r' (unless 's' is flagged)
+	exclude_cr bool
 }
 
 // Generate a new instruction struct for use in regexp. By default, the instr
@@ -199,7 +250,7 @@ func (p *parser) instr() *instr {
 		copy(p.re.prog, local)
 	}
 	p.re.prog = p.re.prog[0 : pos+1]
-	i := &instr{pos, iSplit, nil, nil, bNone, nil, -1, ""}
+	i := &instr{pos, iSplit, nil, nil, bNone, nil, "", -1, ""}
 	p.re.prog[pos] = i
 	return i
 }
@@ -252,6 +303,24 @@ func (p *parser) alt(cname string, capture bool) (start *instr, end *instr) {
 		p.re.caps += 1
 	}
 
+	start = p.branches(end)
+
+	// Note: We don't move over this final bracket.
+	if p.src.curr() != ')' {
+		panic("alt must end with ')'")
+	}
+
+	// Wire up the start of this alt to the first regexp part.
+	p.out(alt_begin, start)
+
+	return alt_begin, end
+}
+
+// Consume one or more regexps separated by '|', wiring each of them to the
+// given shared end instr. Returns the instr which begins the alternation. The
+// cursor will rest on the first character that is neither part of a regexp
+// nor '|', i.e. ')' or EOF.
+func (p *parser) branches(end *instr) (start *instr) {
 	b_start, b_end := p.regexp()
 	start = b_start
 	p.out(b_end, end)
@@ -267,15 +336,7 @@ func (p *parser) alt(cname string, capture bool) (start *instr, end *instr) {
 		b_start = start
 	}
 
-	// Note: We don't move over this final bracket.
-	if p.src.curr() != ')' {
-		panic("alt must end with ')'")
-	}
-
-	// Wire up the start of this alt to the first regexp part.
-	p.out(alt_begin, start)
-
-	return alt_begin, end
+	return start
 }
 
 // Consume a single rune; assumes this is being invoked as the last possible
@@ -304,7 +365,25 @@ func (p *parser) single_rune() int {
 		if err != nil {
 			panic(fmt.Sprintf("couldn't parse hex: %s", hex))
 		}
-		return int(rune)
+		return checkEscapedRune(rune)
+	} else if p.src.peek() == 'N' {
+		// Match a named character. Only the code point form, \N{U+XXXX}, is
+		// supported for now.
+		p.src.nextCh()
+		if p.src.nextCh() != '{' {
+			panic("expected '{' after \\N")
+		}
+		name := p.src.literal("{", "}")
+		if !strings.HasPrefix(name, "U+") {
+			panic(fmt.Sprintf("unsupported character name: %s", name))
+		}
+
+		// Parse and return the corresponding rune.
+		rune, err := strconv.Btoui64(name[2:], 16)
+		if err != nil {
+			panic(fmt.Sprintf("couldn't parse character name: %s", name))
+		}
+		return checkEscapedRune(rune)
 	} else if rune := ESCAPES[p.src.peek()]; rune != 0 {
 		// Literally match '\n', '\r', etc.
 		p.src.nextCh()
@@ -331,16 +410,38 @@ func (p *parser) single_rune() int {
 		if err != nil {
 			panic(fmt.Sprintf("couldn't parse oct: %s", oct))
 		}
-		return int(rune)
+		return checkEscapedRune(rune)
 	}
 
 	// This is an escape sequence which does not identify a single rune.
 	panic(fmt.Sprintf("not a valid escape sequence: \\%c", p.src.peek()))
 }
 
+// Check that a rune given by a hex or octal escape is a valid Unicode scalar
+// value, and return it. Runes beyond unicode.MaxRune, and the surrogate range
+// U+D800-U+DFFF, could never match any input and so panic here.
+func checkEscapedRune(rune uint64) int {
+	if rune > unicode.MaxRune {
+		panic(fmt.Sprintf("escaped rune out of range: %#x", rune))
+	}
+	if rune >= 0xd800 && rune <= 0xdfff {
+		panic(fmt.Sprintf("escaped rune is a surrogate: %#x", rune))
+	}
+	return int(rune)
+}
+
 // Consume a single character class and provide an implementation of the
 // RuneFilter interface. Consumes the entire definition.
 func (p *parser) class(within_class bool) (filter RuneFilter) {
+	filter, _, _ = p.classItem(within_class)
+	return filter
+}
+
+// Consume a single character class, as per class(). If the class was a plain
+// rune literal or range, also returns its bounds as lo and hi; otherwise these
+// are both -1.
+func (p *parser) classItem(within_class bool) (filter RuneFilter, lo int, hi int) {
+	lo, hi = -1, -1
 	negate := false
 	switch p.src.curr() {
 	case '.':
@@ -348,6 +449,10 @@ func (p *parser) class(within_class bool) (filter RuneFilter) {
 			filter = func(rune int) bool {
 				return true
 			}
+		} else if p.exclude_cr {
+			filter = func(rune int) bool {
+				return rune != '\n' && rune != '\r'
+			}
 		} else {
 			filter = func(rune int) bool {
 				return rune != '\n'
@@ -358,7 +463,7 @@ func (p *parser) class(within_class bool) (filter RuneFilter) {
 		if p.src.peek() == ':' {
 			// Match an ASCII/POSIX class name.
 			name := p.src.literal("[:", ":]")
-			if name[0] == '^' {
+			if len(name) > 0 && name[0] == '^' {
 				negate = true
 				name = name[1:]
 			}
@@ -370,30 +475,51 @@ func (p *parser) class(within_class bool) (filter RuneFilter) {
 			filter = func(rune int) bool {
 				return unicode.Is(ranges, rune)
 			}
-		} else {
-			if within_class {
-				panic("can't match a [...] class within another class")
-			}
+		} else if !within_class {
 			if p.src.nextCh() == '^' {
 				negate = true
 				p.src.nextCh()
 			}
 
-			// Consume and merge all valid classes within this [...] block.
+			// Consume and merge all valid classes within this [...] block. Plain
+			// runes and ranges are collected separately, so that adjacent and
+			// overlapping ones can be merged into as few ranges as possible.
 			filters := make([]RuneFilter, 0)
+			ranges := make([]runeRange, 0)
 			for p.src.curr() != ']' {
-				filters = append(filters, p.class(true))
+				if p.src.curr() == -1 {
+					panic("EOF in character class, missing ']'")
+				}
+				f, lo, hi := p.classItem(true)
+				if lo == -1 {
+					filters = append(filters, f)
+				} else {
+					ranges = append(ranges, runeRange{lo, hi})
+				}
 			}
-			filter = func(rune int) bool {
-				for _, f := range filters {
-					if f(rune) {
-						return true
+			for _, r := range mergeRanges(ranges) {
+				if r.lo == r.hi {
+					filters = append(filters, MatchRune(r.lo))
+				} else {
+					filters = append(filters, MatchRuneRange(r.lo, r.hi))
+				}
+			}
+			if len(filters) == 1 {
+				filter = filters[0]
+			} else {
+				filter = func(rune int) bool {
+					for _, f := range filters {
+						if f(rune) {
+							return true
+						}
 					}
+					return false
 				}
-				return false
 			}
 			p.src.nextCh() // Move over final ']'.
 		}
+		// Otherwise, this is a '[' inside a class which doesn't open a posix
+		// name: it's left for the single rune literal case below.
 	case '\\':
 		// Match some escaped character or escaped combination.
 		if p.src.peek() == 'p' || p.src.peek() == 'P' {
@@ -407,7 +533,7 @@ func (p *parser) class(within_class bool) (filter RuneFilter) {
 			}
 
 			// Find and return the class.
-			if filter = matchUnicodeClass(unicode_class); filter == nil {
+			if filter = MatchUnicodeClass(unicode_class); filter == nil {
 				panic(fmt.Sprintf("could not identify unicode class: %s", unicode_class))
 			}
 		} else if ranges, ok := perl_groups[unicode.ToLower(p.src.peek())]; ok {
@@ -432,16 +558,24 @@ func (p *parser) class(within_class bool) (filter RuneFilter) {
 			if rune_high < rune {
 				panic(fmt.Sprintf("unexpected range: %c >= %c", rune, rune_high))
 			}
-			filter = matchRuneRange(rune, rune_high)
+			filter = MatchRuneRange(rune, rune_high)
+			lo, hi = rune, rune_high
 		} else {
-			filter = matchRune(rune)
+			filter = MatchRune(rune)
+			lo, hi = rune, rune
 		}
 	}
 
+	if p.flag('i') {
+		// Mark this class as case-insensitive. This must happen before negation,
+		// so that e.g. (?i)[^a-z] doesn't match 'A'.
+		filter = filter.IgnoreCase()
+	}
+
 	if negate {
-		return filter.not()
+		return filter.Not(), -1, -1
 	}
-	return filter
+	return filter, lo, hi
 }
 
 // Build a left-right matcher of the given mode.
@@ -460,7 +594,8 @@ func (p *parser) term() (start *instr, end *instr) {
 	case -1:
 		panic("EOF in term")
 	case '*', '+', '{', '?':
-		panic(fmt.Sprintf("unexpected expansion char: %c at %d", p.src.curr(), p.src.opos))
+		// A quantifier with no term before it, e.g. "*abc" or "a|+b".
+		panic(fmt.Sprintf("nothing to repeat: %c at %d", p.src.curr(), p.src.opos))
 	case ')', '}', ']':
 		panic("unexpected close element")
 	case '(':
@@ -493,7 +628,7 @@ func (p *parser) term() (start *instr, end *instr) {
 						// now we're clearing flags
 						set = false
 					default:
-						if p.src.curr() < 64 && p.src.curr() > 127 {
+						if p.src.curr() < 64 || p.src.curr() > 127 {
 							panic(fmt.Sprintf("flag not in range: %c", p.src.curr()))
 						}
 						flag := byte(p.src.curr() - 64)
@@ -549,7 +684,11 @@ func (p *parser) term() (start *instr, end *instr) {
 			for _, rune := range literal {
 				instr := p.instr()
 				instr.mode = iRuneClass
-				instr.rune = matchRune(rune)
+				instr.rune = MatchRune(rune)
+				instr.desc = "'" + string(rune) + "'"
+				if p.flag('i') {
+					instr.rune = instr.rune.IgnoreCase()
+				}
 				p.out(end, instr)
 				end = instr
 			}
@@ -574,20 +713,62 @@ func (p *parser) term() (start *instr, end *instr) {
 			p.src.consume("\\B")
 			start = p.makeBoundaryInstr(bNotWordBoundary)
 			return start, start
+		case '<':
+			// Match the start of an ASCII word.
+			p.src.consume("\\<")
+			start = p.makeBoundaryInstr(bBeginWord)
+			return start, start
+		case '>':
+			// Match the end of an ASCII word.
+			p.src.consume("\\>")
+			start = p.makeBoundaryInstr(bEndWord)
+			return start, start
 		}
 	}
 
 	// Try to consume a rune class.
 	start = p.instr()
 	start.mode = iRuneClass
+	from := p.src.opos
 	start.rune = p.class(false)
+	start.desc = p.describeClass(p.src.str[from:p.src.opos])
+	return start, start
+}
 
+// Describe the rune class consumed from the given source text, for Explain.
+func (p *parser) describeClass(text string) (desc string) {
+	switch text {
+	case ".":
+		if p.flag('s') {
+			desc = "any rune"
+		} else {
+			desc = "any rune but newline"
+		}
+	case "\\d":
+		desc = "a digit"
+	case "\\D":
+		desc = "a non-digit"
+	case "\\w":
+		desc = "a word character"
+	case "\\W":
+		desc = "a non-word character"
+	case "\\s":
+		desc = "a space"
+	case "\\S":
+		desc = "a non-space"
+	default:
+		if strings.HasPrefix(text, "[") {
+			desc = "one of " + text
+		} else if strings.HasPrefix(text, "\\p") || strings.HasPrefix(text, "\\P") {
+			desc = "a rune in " + text
+		} else {
+			desc = "'" + text + "'"
+		}
+	}
 	if p.flag('i') {
-		// Mark this class as case-insensitive.
-		start.rune = start.rune.ignoreCase()
+		desc += " (ignoring case)"
 	}
-
-	return start, start
+	return desc
 }
 
 // Safely retrieve a given term from the given position and alt count. If the
@@ -659,10 +840,26 @@ func (p *parser) closure() (start *instr, end *instr) {
 		return t_start, t_end // nothing to see here
 	}
 
+	lazy := false
 	if p.src.curr() == '?' {
 		greedy = !greedy
+		lazy = true
 		p.src.nextCh()
 	}
+
+	// A possessive '+' may follow a quantifier which isn't lazy, e.g. "a*+". This
+	// engine can't forbid giving back what a closure consumed, so the '+' just
+	// makes the closure greedy: "a*+" matches as "a*".
+	if !lazy && p.src.curr() == '+' {
+		greedy = true
+		p.src.nextCh()
+	}
+
+	// Quantifiers can't be stacked, e.g. "a**" or "a+?*".
+	switch p.src.curr() {
+	case '*', '?', '+', '{':
+		panic(fmt.Sprintf("invalid repeated quantifier: %c at %d", p.src.curr(), p.src.opos))
+	}
 	end_src := p.src
 
 	if req < 0 || opt < -1 || req == 0 && opt == 0 {
@@ -745,39 +942,15 @@ func (p *parser) regexp() (start *instr, end *instr) {
 // the canonical entry point for the regexp.
 // Returns a similarly flat slice containing no nil instructions, however the
 // slice may potentially be smaller.
+// Loops made only of iSplits, e.g. from \Q\E* or (?:)+, are left in place, as
+// the matchers visit each iSplit at most once per position.
 func cleanup(prog []*instr) []*instr {
-	// Detect iSplit recursion. We can remove this and convert it to a single path.
-	// This might happen in cases where we loop over some instructions which are
-	// not matchers, e.g. \Q\E*.
-	for i := 1; i < len(prog); i++ {
-		states := make(map[int]bool)
-		pi := prog[i]
-		var fn func(ci *instr) bool
-		fn = func(ci *instr) bool {
-			if ci != nil && ci.mode == iSplit {
-				if _, exists := states[ci.idx]; exists {
-					// We've found a recursion.
-					return true
-				}
-				states[ci.idx] = true
-				if fn(ci.out) {
-					ci.out = nil
-				}
-				if fn(ci.out1) {
-					ci.out1 = nil
-				}
-				return false
-			}
-			return false
-		}
-		fn(pi)
-	}
-
 	// Iterate through the program, and remove single-instr iSplits.
 	// NB: Don't parse the first instr, it will always be single.
 	for i := 1; i < len(prog); i++ {
 		pi := prog[i]
-		if pi.mode == iSplit && (pi.out1 == nil || pi.out == pi.out1) {
+		// A single iSplit looping to itself can't be bypassed, so it is kept.
+		if pi.mode == iSplit && (pi.out1 == nil || pi.out == pi.out1) && pi.out != pi {
 			for j := 0; j < len(prog); j++ {
 				if prog[j] == nil {
 					continue
@@ -825,9 +998,36 @@ func cleanup(prog []*instr) []*instr {
 // Public interface to a compiled regexp.
 type Re interface {
 	NumSubexps() int
+	SubexpNames() []string
 	Match(s string) bool
 	MatchIndex(s string) []int
 	Extract(src string, max int) []string
+	ExtractRange(src string, from, to int) []string
+	ExtractNamed(src string) map[string]string
+	ExtractInto(src string, dst []string) []string
+	MatchRunes(runes []int) bool
+	FindRunesIndex(runes []int) []int
+	FindAllSubmatch(src string, n int) [][]string
+	FindAll(src string, n int) [][]string
+	FindAllIndex(src string, n int) [][]int
+	GroupStats(src string, n int) []int
+	Find(src string) []string
+	FindResult(src string) (*MatchResult, bool)
+	ReplaceFirst(src string, repl string) string
+	ReplaceAll(src string, repl string) string
+	ReplaceAllLiteral(src string, repl string) string
+	ReplaceAllFunc(src string, f func(match string) string) string
+	Split(src string, n int) []string
+	ReplaceAllTemplate(src string, template string) string
+	Global() *GlobalRe
+	FirstRunes() (runes []int, any bool)
+	IsSubset(other Re) bool
+	Flags() string
+	Explain() string
+	Program() []Instruction
+	MatchWithFuel(src string, maxSteps int) (matched bool, exhausted bool)
+	LongestMatchAt(src string, pos int) (length int, ok bool)
+	MatchBatch(inputs []string) []bool
 	DebugOut()
 }
 
@@ -855,6 +1055,23 @@ func (p *parser) makeDotStarOpt() (begin *instr, final *instr) {
 // given input string. If the regexp could not be parsed, returns a non-nil
 // error string: the regexp will be nil in this case.
 func Parse(src string) (re Re, err *string) {
+	return parse(src, 0, false)
+}
+
+// Generates a NFA as per Parse, but with control over what '.' matches. If
+// dotAll is set, '.' matches every rune, as if the regexp began with "(?s)".
+// Otherwise it matches all but '\n' and, if excludeCR is set, '\r'; this
+// suits text with Windows line endings.
+func ParseWithDotMode(src string, dotAll bool, excludeCR bool) (re Re, err *string) {
+	var flags int64
+	if dotAll {
+		flags |= 1 << byte('s'-64)
+	}
+	return parse(src, flags, excludeCR)
+}
+
+// Parse the given source with the given initial flags, see Parse().
+func parse(src string, flags int64, exclude_cr bool) (re Re, err *string) {
 	defer func() {
 		if r := recover(); r != nil {
 			re = nil // clear re so it can't be used by caller
@@ -868,7 +1085,7 @@ func Parse(src string) (re Re, err *string) {
 		}
 	}()
 
-	p := parser{&sregexp{make([]*instr, 0, 1), -1, 1}, NewSafeReader(src), 0}
+	p := parser{&sregexp{prog: make([]*instr, 0, 1), start: -1, caps: 1}, NewSafeReader(src), flags, exclude_cr}
 
 	// generate the prefix, ala ".*?("
 	// note that this has to come first, since it represents instruction zero
@@ -883,17 +1100,23 @@ func Parse(src string) (re Re, err *string) {
 	match.mode = iMatch
 
 	// parse and consume the regexp, placing it between prefix/suffix.
+	// The top level may itself be an alternation, e.g. "(?i)|abc".
 	p.src.nextCh()
-	re_start, re_end := p.regexp()
+	re_end := p.instr()
+	re_start := p.branches(re_end)
 	if p.src.curr() != -1 {
 		panic("could not consume all of regexp!")
 	}
 	p.out(prefix, re_start)
 	p.out(re_end, suffix)
+	p.re.flags = p.flags
 
 	// cleanup and return success
 	p.re.prog = cleanup(p.re.prog)
 
+	// Start past the single iSplit at zero, reading its (possibly compacted)
+	// target only now that cleanup is done.
+	p.re.start = 0
 	if p.re.prog[0].out1 == nil {
 		p.re.start = p.re.prog[0].out.idx
 	}
@@ -3,6 +3,9 @@ package sre2
 import (
 	//"container/list"
 	//"fmt"
+	"runtime"
+	"strconv"
+	"utf8"
 )
 
 
@@ -18,7 +21,20 @@ func (r *sregexp) MatchIndex(src string) []int {
 }
 
 func (r *sregexp) Extract(src string, max int) []string {
-	captured_texts := make([]string, 0)
+	return r.appendExtract(make([]string, 0), src, max)
+}
+
+// ExtractInto is as Extract with no limit, but appends the captured texts to
+// dst and returns the extended slice, as append does. Passing dst[:0] from a
+// previous call reuses its storage, so a loop over many inputs need not
+// allocate a new slice per match.
+func (r *sregexp) ExtractInto(src string, dst []string) []string {
+	return r.appendExtract(dst, src, r.caps)
+}
+
+// appendExtract appends the text of up to max+1 participating groups of the
+// match of src to captured_texts.
+func (r *sregexp) appendExtract(captured_texts []string, src string, max int) []string {
 	index := 0
 	if e, capture := r.run(src, true); e == true {
 
@@ -48,28 +64,513 @@ func (r *sregexp) Extract(src string, max int) []string {
 	return captured_texts
 }
 
+// ExtractRange returns the text of groups from..to inclusive, where group 0 is
+// the whole match. Groups which did not participate give "". Returns nil if
+// src does not match or the range is out of bounds.
+func (r *sregexp) ExtractRange(src string, from, to int) []string {
+	if from < 0 || from > to || to >= r.caps {
+		return nil
+	}
+	e, capture := r.run(src, true)
+	if !e {
+		return nil
+	}
+	captured_texts := make([]string, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		begin_pos := capture[i*2]
+		end_pos := capture[i*2+1]
+		if begin_pos == -1 || end_pos == -1 {
+			captured_texts = append(captured_texts, "")
+			continue
+		}
+		captured_texts = append(captured_texts, src[begin_pos:end_pos])
+	}
+	return captured_texts
+}
+
+// ExtractNamed returns the text of each named group of the match of src, by
+// name, with "" for a group which did not participate. Where names repeat, the
+// first such group is used, as for ${name} in ReplaceAllTemplate. Returns nil
+// if src does not match.
+func (r *sregexp) ExtractNamed(src string) map[string]string {
+	e, capture := r.run(src, true)
+	if !e {
+		return nil
+	}
+	named := make(map[string]string)
+	for g, name := range r.SubexpNames() {
+		if _, seen := named[name]; seen || len(name) == 0 {
+			continue
+		}
+		named[name] = ""
+		if capture[g*2] != -1 && capture[g*2+1] != -1 {
+			named[name] = src[capture[g*2]:capture[g*2+1]]
+		}
+	}
+	return named
+}
+
+
+// MatchRunes is as Match, but over an already decoded slice of runes.
+func (r *sregexp) MatchRunes(runes []int) bool {
+	src, _ := encodeRunes(runes)
+	return r.Match(src)
+}
+
+// FindRunesIndex is as MatchIndex, but over an already decoded slice of runes.
+// The returned offsets are indexes into runes, rather than byte offsets.
+func (r *sregexp) FindRunesIndex(runes []int) []int {
+	src, rune_index := encodeRunes(runes)
+	capture := r.MatchIndex(src)
+	for i := 0; i < len(capture); i++ {
+		if capture[i] != -1 {
+			capture[i] = rune_index[capture[i]]
+		}
+	}
+	return capture
+}
+
+// Encode the given runes as a UTF-8 string. Also returns a mapping from the
+// byte offset of each rune boundary in the string to the rune's index.
+func encodeRunes(runes []int) (string, []int) {
+	buf := make([]byte, 0, len(runes)*utf8.UTFMax)
+	rune_index := make([]int, 0, cap(buf)+1)
+	var tmp [utf8.UTFMax]byte
+	for i, rune := range runes {
+		n := utf8.EncodeRune(tmp[:], rune)
+		for j := 0; j < n; j++ {
+			rune_index = append(rune_index, i)
+		}
+		buf = append(buf, tmp[:n]...)
+	}
+	rune_index = append(rune_index, len(runes))
+	return string(buf), rune_index
+}
+
+// FindAllSubmatch returns the text of every group, as per ExtractRange over all
+// groups, for each successive non-overlapping match in src. If n >= 0, at most
+// n matches are returned. Returns nil if there is no match.
+func (r *sregexp) FindAllSubmatch(src string, n int) [][]string {
+	var matches [][]string
+	for _, capture := range r.findAllIndex(src, n) {
+		groups := make([]string, r.caps)
+		for i := 0; i < r.caps; i++ {
+			if capture[i*2] != -1 && capture[i*2+1] != -1 {
+				groups[i] = src[capture[i*2]:capture[i*2+1]]
+			}
+		}
+		matches = append(matches, groups)
+	}
+	return matches
+}
+
+// FindAll is FindAllSubmatch: the text of every group of each successive
+// non-overlapping match in src, at most n matches if n >= 0.
+func (r *sregexp) FindAll(src string, n int) [][]string {
+	return r.FindAllSubmatch(src, n)
+}
+
+// FindAllIndex returns, for each successive non-overlapping match in src, the
+// byte offsets of every group as per MatchIndex, with -1 for groups which did
+// not take part. If n >= 0, at most n matches are returned. Returns nil if
+// there is no match.
+func (r *sregexp) FindAllIndex(src string, n int) [][]int {
+	return r.findAllIndex(src, n)
+}
+
+// GroupStats counts, for each group as per FindAllSubmatch (with group 0 the
+// whole match), how many of the successive non-overlapping matches in src it
+// took part in. If n >= 0, at most n matches are counted.
+func (r *sregexp) GroupStats(src string, n int) []int {
+	counts := make([]int, r.caps)
+	for _, capture := range r.findAllIndex(src, n) {
+		for i := 0; i < r.caps; i++ {
+			if capture[i*2] != -1 && capture[i*2+1] != -1 {
+				counts[i]++
+			}
+		}
+	}
+	return counts
+}
+
+// Find returns the text of the first match in src, as a slice of at most one
+// string. See Global for a Find over all matches.
+func (r *sregexp) Find(src string) []string {
+	return r.find(src, 1)
+}
+
+// MatchResult locates a match both by byte offsets, for slicing the source
+// string, and by rune offsets, e.g. for showing positions to users.
+type MatchResult struct {
+	ByteStart, ByteEnd int
+	RuneStart, RuneEnd int
+}
+
+// FindResult locates the leftmost match in src, returning false if there is
+// none.
+func (r *sregexp) FindResult(src string) (*MatchResult, bool) {
+	e, capture := r.run(src, true)
+	if !e {
+		return nil, false
+	}
+	res := &MatchResult{ByteStart: capture[0], ByteEnd: capture[1]}
+	res.RuneStart = utf8.RuneCountInString(src[:res.ByteStart])
+	res.RuneEnd = res.RuneStart + utf8.RuneCountInString(src[res.ByteStart:res.ByteEnd])
+	return res, true
+}
+
+// ReplaceFirst returns src with only its leftmost match replaced by the literal
+// repl. The rest of src, including any later matches, is left untouched. See
+// Global for a Replace over all matches.
+func (r *sregexp) ReplaceFirst(src string, repl string) string {
+	return r.replace(src, repl, 1)
+}
+
+// ReplaceAll returns src with every non-overlapping match replaced by repl,
+// expanded as for ReplaceAllTemplate, so that "$3/$2/$1" turns "2009-12-31"
+// into "31/12/2009" for "(\d+)-(\d+)-(\d+)". Empty matches are replaced
+// too, except one directly after a previous match, so "x*" over "abc" gives
+// "-a-b-c-" for repl "-", but "a*" over "baaac" gives "-b-c-".
+func (r *sregexp) ReplaceAll(src string, repl string) string {
+	return r.ReplaceAllTemplate(src, repl)
+}
+
+// ReplaceAllLiteral is as ReplaceAll, but repl is used as is, with no '$'
+// expansion.
+func (r *sregexp) ReplaceAllLiteral(src string, repl string) string {
+	return r.replace(src, repl, -1)
+}
+
+// ReplaceAllFunc is as ReplaceAllLiteral, but replaces each match with the
+// result of f for the text of that match. Matches are found, and f called,
+// from left to right; text between matches is left untouched.
+func (r *sregexp) ReplaceAllFunc(src string, f func(match string) string) string {
+	return r.replaceFunc(src, -1, func(capture []int) string {
+		return f(src[capture[0]:capture[1]])
+	})
+}
+
+// Split slices src into the substrings between matches, as regexp.Split:
+// with n > 0, at most n substrings, the last holding the unsplit rest of src;
+// with n == 0, nil; and with n < 0, all of them. Empty matches split between
+// runes, so "x*" splits "abc" into "a", "b" and "c", but an empty match at
+// the start of src gives no leading "".
+func (r *sregexp) Split(src string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	if len(src) == 0 {
+		return []string{""}
+	}
+
+	matches := r.findAllIndex(src, n)
+	parts := make([]string, 0, len(matches)+1)
+	begin, end := 0, 0
+	for _, capture := range matches {
+		if n > 0 && len(parts) == n-1 {
+			break
+		}
+		end = capture[0]
+		if capture[1] != 0 {
+			parts = append(parts, src[begin:end])
+		}
+		begin = capture[1]
+	}
+	if end != len(src) {
+		parts = append(parts, src[begin:])
+	}
+	return parts
+}
+
+// Global returns a wrapper of this regexp whose Find and Replace operate on
+// every match, as with a JavaScript regexp flagged 'g'.
+func (r *sregexp) Global() *GlobalRe {
+	return &GlobalRe{r}
+}
+
+// GlobalRe wraps a regexp so that Find and Replace operate on every match,
+// rather than the first. Build one with Re.Global.
+type GlobalRe struct {
+	r *sregexp
+}
+
+// Find returns the text of every non-overlapping match in src.
+func (g *GlobalRe) Find(src string) []string {
+	return g.r.find(src, -1)
+}
+
+// Replace returns src with every non-overlapping match replaced by the literal
+// repl.
+func (g *GlobalRe) Replace(src string, repl string) string {
+	return g.r.ReplaceAllLiteral(src, repl)
+}
+
+// Return the text of at most n matches in src, or all if n < 0.
+func (r *sregexp) find(src string, n int) []string {
+	var matches []string
+	for _, capture := range r.findAllIndex(src, n) {
+		matches = append(matches, src[capture[0]:capture[1]])
+	}
+	return matches
+}
+
+// Replace at most n matches in src with repl, or all if n < 0.
+func (r *sregexp) replace(src string, repl string, n int) string {
+	return r.replaceFunc(src, n, func(capture []int) string {
+		return repl
+	})
+}
+
+// Replace at most n matches in src, or all if n < 0, with the result of fn for
+// the capture offsets of each.
+func (r *sregexp) replaceFunc(src string, n int, fn func(capture []int) string) string {
+	out := ""
+	last := 0
+	for _, capture := range r.findAllIndex(src, n) {
+		out += src[last:capture[0]] + fn(capture)
+		last = capture[1]
+	}
+	return out + src[last:]
+}
+
+// ReplaceAllTemplate replaces every match of src, as ReplaceAll, with template
+// expanded for that match: $n
+// or ${n} gives the text of group n (with $0 the whole match), ${name} that of
+// the group named by (?P<name>...), and $$ a literal '$'. A group which is
+// unknown, or which did not participate in the match, gives "".
+func (r *sregexp) ReplaceAllTemplate(src string, template string) string {
+	return r.replaceFunc(src, -1, func(capture []int) string {
+		return r.expand(template, src, capture)
+	})
+}
+
+// Expand template for a single match of src, see ReplaceAllTemplate.
+func (r *sregexp) expand(template string, src string, capture []int) string {
+	out := ""
+	for i := 0; i < len(template); i++ {
+		if template[i] != '$' || i+1 == len(template) {
+			out += template[i : i+1]
+			continue
+		}
+
+		// Find the group reference following this '$'.
+		var ref string
+		switch next := template[i+1]; {
+		case next == '$':
+			out += "$"
+			i++
+			continue
+		case next == '{':
+			end := i + 2
+			for end < len(template) && template[end] != '}' {
+				end++
+			}
+			if end == len(template) {
+				out += template[i:]
+				return out
+			}
+			ref = template[i+2 : end]
+			i = end
+		case next >= '0' && next <= '9':
+			end := i + 1
+			for end < len(template) && template[end] >= '0' && template[end] <= '9' {
+				end++
+			}
+			ref = template[i+1 : end]
+			i = end - 1
+		default:
+			out += "$"
+			continue
+		}
+
+		if g := r.groupIndex(ref); g != -1 && capture[g*2] != -1 && capture[g*2+1] != -1 {
+			out += src[capture[g*2]:capture[g*2+1]]
+		}
+	}
+	return out
+}
+
+// Find the index of the group referred to by ref, either its number or the
+// name given by (?P<name>...). Returns -1 if there is no such group.
+func (r *sregexp) groupIndex(ref string) int {
+	if g, err := strconv.Atoi(ref); err == nil {
+		if g < 0 || g >= r.caps {
+			return -1
+		}
+		return g
+	}
+	for _, i := range r.prog {
+		if i.mode == iIndexCap && len(i.cname) != 0 && i.cname == ref {
+			return i.cid / 2
+		}
+	}
+	return -1
+}
+
+// Find the capture offsets of successive non-overlapping matches in src, at
+// most n of them if n >= 0. An empty match directly after the previous match
+// is skipped, and otherwise the search moves one rune past an empty match.
+func (r *sregexp) findAllIndex(src string, n int) [][]int {
+	var matches [][]int
+	pos, prev_end := 0, -1
+	for pos <= len(src) && (n < 0 || len(matches) < n) {
+		e, capture := r.runFrom(src, pos, true)
+		if !e {
+			break
+		}
+		begin_pos, end_pos := capture[0], capture[1]
+		if begin_pos != end_pos || begin_pos != prev_end {
+			matches = append(matches, capture)
+		}
+		prev_end = end_pos
+
+		// Move past this match, or past one rune if it was empty.
+		pos = end_pos
+		if begin_pos == end_pos {
+			if pos == len(src) {
+				break
+			}
+			_, size := utf8.DecodeRuneInString(src[pos:])
+			pos += size
+		}
+	}
+	return matches
+}
 
 func (r *sregexp) run(src string, submatch bool) (success bool, capture []int) {
+	return r.runFrom(src, 0, submatch)
+}
+
+// runFrom is as run, but begins matching at byte offset from within src. The
+// rune before from is still visible to boundaries such as '^' and '\b', and
+// any captured offsets are relative to the start of src.
+func (r *sregexp) runFrom(src string, from int, submatch bool) (success bool, capture []int) {
 	curr := makeStateList(len(r.prog))
 	next := makeStateList(len(r.prog))
 	parser := NewSafeReader(src)
+	if from > 0 {
+		_, size := utf8.DecodeLastRuneInString(src[:from])
+		parser.jump(from - size)
+	}
 
-	return r._run(curr, next, &parser, src, submatch)
+	success, capture, _ = r._run(curr, next, &parser, src, submatch, -1)
+	return success, capture
 }
 
+// LongestMatchAt returns the length in bytes of the longest match of this
+// regexp beginning exactly at byte offset pos in src, as a maximal-munch lexer
+// needs. Unlike Match, every thread is followed to the end, and a match may not
+// start later than pos. The rune before pos is still visible to boundaries
+// such as '^' and '\b'. Returns false if nothing matches at pos.
+func (r *sregexp) LongestMatchAt(src string, pos int) (length int, ok bool) {
+	if pos < 0 || pos > len(src) {
+		return 0, false
+	}
+	curr := makeStateList(len(r.prog))
+	next := makeStateList(len(r.prog))
+	parser := NewSafeReader(src)
+	if pos > 0 {
+		_, size := utf8.DecodeLastRuneInString(src[:pos])
+		parser.jump(pos - size)
+	}
 
-func (r *sregexp) _run(curr *stateList, next *stateList, parser *SafeReader, src string, submatch bool) (success bool, capture []int) {
+	// Begin past the ".*?" prefix, so that the match starts at pos.
+	if curr.addlongest(&parser, r.userStart()) {
+		length, ok = 0, true
+	}
+	for len(curr.states) != 0 && parser.nextCh() != -1 {
+		ch := parser.curr()
+		for _, st := range curr.states {
+			i := r.prog[st.idx]
+			if i.match(ch) && next.addlongest(&parser, i.out) {
+				length, ok = parser.npos()-pos, true
+			}
+		}
+		curr, next = next, curr
+		next.clear()
+	}
+	return length, ok
+}
+
+// MatchWithFuel is as Match, but gives up once maxSteps instrs have been
+// evaluated against input runes, returning exhausted. This gives a fixed bound
+// on the cost of matching untrusted input. A negative maxSteps has no limit.
+func (r *sregexp) MatchWithFuel(src string, maxSteps int) (matched bool, exhausted bool) {
+	curr := makeStateList(len(r.prog))
+	next := makeStateList(len(r.prog))
+	parser := NewSafeReader(src)
+	matched, _, exhausted = r._run(curr, next, &parser, src, false, maxSteps)
+	return matched, exhausted
+}
+
+
+// Batches of at least this many inputs are split across goroutines by
+// MatchBatch.
+const batchParallelMin = 256
+
+// MatchBatch returns whether each of inputs matches, as Match, in the same
+// order. State lists are allocated once and reused across the batch; large
+// batches are shared out across GOMAXPROCS goroutines, each with its own.
+func (r *sregexp) MatchBatch(inputs []string) []bool {
+	results := make([]bool, len(inputs))
+	procs := runtime.GOMAXPROCS(0)
+	if procs < 2 || len(inputs) < batchParallelMin {
+		r.matchBatch(inputs, results)
+		return results
+	}
+
+	chunk := (len(inputs) + procs - 1) / procs
+	done := make(chan bool)
+	workers := 0
+	for begin := 0; begin < len(inputs); begin += chunk {
+		end := begin + chunk
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+		workers++
+		go func(begin, end int) {
+			r.matchBatch(inputs[begin:end], results[begin:end])
+			done <- true
+		}(begin, end)
+	}
+	for ; workers > 0; workers-- {
+		<-done
+	}
+	return results
+}
+
+// matchBatch stores whether each of inputs matches in results, reusing one
+// pair of state lists.
+func (r *sregexp) matchBatch(inputs []string, results []bool) {
+	curr := makeStateList(len(r.prog))
+	next := makeStateList(len(r.prog))
+	for i, src := range inputs {
+		curr.clear()
+		next.clear()
+		parser := NewSafeReader(src)
+		results[i], _, _ = r._run(curr, next, &parser, src, false, -1)
+	}
+}
+
+
+// Run the regexp over src. Each instr evaluated against a rune uses one unit
+// of fuel; if that runs out, exhausted is set. Negative fuel has no limit.
+func (r *sregexp) _run(curr *stateList, next *stateList, parser *SafeReader, src string, submatch bool, fuel int) (success bool, capture []int, exhausted bool) {
 	// always start with state zero
 	curr.addstate(parser, r.prog[r.start], submatch, nil)
 
 	for parser.nextCh() != -1 {
 		ch := parser.curr()
 		if len(curr.states) == 0 {
-			return false, nil // no more possible states, short-circuit failure
+			return false, nil, false // no more possible states, short-circuit failure
 		}
 
 		// move along rune paths
 		for _, st := range curr.states {
+			if fuel == 0 {
+				return false, nil, true
+			}
+			fuel--
 			i := r.prog[st.idx]
 			if i.match(ch) {
 				next.addstate(parser, i.out, submatch, st.capture)
@@ -82,10 +583,10 @@ func (r *sregexp) _run(curr *stateList, next *stateList, parser *SafeReader, src
 	// search for success state
 	for _, st := range curr.states {
 		if r.prog[st.idx].mode == iMatch {
-			return true, st.capture.list(r.caps)
+			return true, st.capture.list(r.caps), false
 		}
 	}
-	return false, nil
+	return false, nil, false
 }
 
 // stateList is used by regexp.run() to efficiently maintain an ordered list of
@@ -111,8 +612,14 @@ func makeStateList(states int) *stateList {
 func (o *stateList) addstate(p *SafeReader, st *instr, submatch bool, capture *captureInfo) {
 	switch st.mode {
 	case iSplit:
+		// Visit each iSplit once, so that loops of them (e.g. "\Q\E*") end.
+		if o.put(st.idx, capture) {
+			return
+		}
 		o.addstate(p, st.out, submatch, capture)
-		o.addstate(p, st.out1, submatch, capture)
+		if st.out1 != nil {
+			o.addstate(p, st.out1, submatch, capture)
+		}
 	case iIndexCap:
 		if submatch {
 			capture = capture.push(p.npos(), st.cid)
@@ -129,6 +636,39 @@ func (o *stateList) addstate(p *SafeReader, st *instr, submatch bool, capture *c
 	}
 }
 
+// addlongest descends as addstate does, without tracking submatches, for
+// LongestMatchAt. Rather than following the ".*?" suffix, returns true if the
+// end of the outermost group is reached.
+func (o *stateList) addlongest(p *SafeReader, st *instr) (matched bool) {
+	switch st.mode {
+	case iSplit:
+		if o.put(st.idx, nil) {
+			return false
+		}
+		matched = o.addlongest(p, st.out)
+		if st.out1 != nil && o.addlongest(p, st.out1) {
+			matched = true
+		}
+		return matched
+	case iIndexCap:
+		if st.cid == 1 {
+			return true
+		}
+		return o.addlongest(p, st.out)
+	case iBoundaryCase:
+		if st.matchBoundaryMode(p.curr(), p.peek()) {
+			return o.addlongest(p, st.out)
+		}
+	case iRuneClass:
+		o.put(st.idx, nil)
+	case iMatch:
+		return true
+	default:
+		panic("unexpected instr")
+	}
+	return false
+}
+
 // put places the given state into the stateList. Returns true if the state was
 // previously set, and false if it was not.
 func (o *stateList) put(v int, capture *captureInfo) bool {
@@ -2,6 +2,7 @@ package sre2
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -78,6 +79,31 @@ func TestInvalidRe(t *testing.T) {
 	checkState(t, err != nil, "must fail parsing")
 	checkState(t, r == nil, "regexp must be nil")
 
+	for _, src := range []string{"a**", "a+*", "a+?*", "a???", "a*{2}", "a{2}*", "a{2}{3}", "a*++", "a+?+"} {
+		_, err := Parse(src)
+		checkState(t, err != nil && strings.Contains(*err, "invalid repeated quantifier"),
+			"stacked quantifiers must fail: "+src)
+	}
+	_, err = Parse("a+?*")
+	checkState(t, err != nil && strings.Contains(*err, "* at 3"), "error should give position")
+
+	leading := map[string]string{"*abc": "* at 0", "?x": "? at 0", "{2}y": "{ at 0", "+x": "+ at 0",
+		"a|*b": "* at 2", "(*a)": "* at 1"}
+	for src, pos := range leading {
+		r, err := Parse(src)
+		checkState(t, r == nil && err != nil && strings.Contains(*err, "nothing to repeat: "+pos),
+			"leading quantifier must fail with its position: "+src)
+	}
+	_, err = Parse("a**")
+	checkState(t, err != nil && strings.Contains(*err, "* at 2"), "error should give position")
+	checkState(t, MustParse("^a*+$").Match("aaa"), "possessive star is allowed")
+	checkState(t, MustParse("^a++b$").Match("aab"), "possessive plus is allowed")
+	checkState(t, !MustParse("^a++$").Match(""), "possessive plus still needs a match")
+	checkState(t, MustParse("^a?+b$").Match("ab"), "possessive optional is allowed")
+	checkState(t, MustParse("(?U)^(a*+)").Extract("aaa", 1)[1] == "aaa", "possessive should be greedy")
+	checkState(t, MustParse("^a+?$").Match("aa"), "lazy quantifier is allowed")
+	checkState(t, MustParse("^a??b$").Match("ab"), "lazy optional is allowed")
+
 	pass := false
 	func() {
 		defer func() {
@@ -139,6 +165,52 @@ func TestCharClass(t *testing.T) {
 	checkState(t, r.Match("]"), "should match ']'")
 	checkState(t, r.Match("["), "should match '['")
 	checkState(t, r.Match("\\"), "should match '\\', between [ and ]")
+
+	r = MustParse("^[[:alpha:]0-9_]+$")
+	checkState(t, r.Match("abc_XYZ_09"), "should match letters, digits and underscore")
+	checkState(t, !r.Match("abc-09"), "should not match '-'")
+
+	r = MustParse("^[[:alpha:]0-9]$")
+	checkState(t, r.Match("q"), "should match letter via posix class")
+	checkState(t, r.Match("7"), "should match digit via range")
+	checkState(t, !r.Match("_"), "should not match '_'")
+
+	r = MustParse("^[a-z[:space:]]+$")
+	checkState(t, r.Match("hello there\tworld"), "should match lowercase and space")
+	checkState(t, !r.Match("Hello"), "should not match uppercase")
+
+	r = MustParse("^[a\\]b]$")
+	checkState(t, r.Match("a"), "should match 'a'")
+	checkState(t, r.Match("]"), "should match escaped ']'")
+	checkState(t, r.Match("b"), "should match 'b' after escaped ']'")
+	checkState(t, !r.Match("\\"), "should not match '\\'")
+
+	r = MustParse("^[\\[\\]]+$")
+	checkState(t, r.Match("[]]["), "should match escaped brackets")
+	checkState(t, !r.Match("a"), "should only match brackets")
+
+	_, err := Parse("[ab\\]")
+	checkState(t, err != nil, "class without closing ']' should fail")
+
+	r = MustParse("^[a^b]$")
+	checkState(t, r.Match("^"), "mid-class caret should be literal")
+	checkState(t, r.Match("a") && r.Match("b"), "should match the other runes")
+	checkState(t, !r.Match("c"), "mid-class caret should not negate")
+
+	r = MustParse("^[^ab]$")
+	checkState(t, !r.Match("a") && !r.Match("b"), "leading caret should negate")
+	checkState(t, r.Match("^"), "negated class should match a caret")
+
+	r = MustParse("^[^^]$")
+	checkState(t, !r.Match("^"), "second caret should be literal and negated")
+	checkState(t, r.Match("a"), "should match anything else")
+
+	r = MustParse("^[ab^]+$")
+	checkState(t, r.Match("a^b^"), "trailing caret should be literal")
+
+	r = MustParse("^[a[]+$")
+	checkState(t, r.Match("a[a"), "'[' not followed by ':' is literal within a class")
+	checkState(t, !r.Match(":"), "should not match ':'")
 }
 
 // Test regexp generated by escape sequences (e.g. \n, \. etc).
@@ -152,7 +224,31 @@ func TestEscapeSequences(t *testing.T) {
 	r = MustParse("^\\x{03a0}\\x25$") // Match 'Π%'.
 	checkState(t, r.Match("Π%"), "should match pi+percent")
 
-	r, err := Parse("^\\Π$")
+	r = MustParse("^\\N{U+0041}\\N{U+03A0}$") // Match 'AΠ'.
+	checkState(t, r.Match("AΠ"), "should match named characters")
+	checkState(t, !r.Match("aΠ"), "should not match lowercase")
+
+	r = MustParse("^[\\N{U+0061}-\\N{U+0063}]+$")
+	checkState(t, r.Match("abc"), "should allow named characters in a range")
+
+	_, err := Parse("\\N{LATIN SMALL LETTER A}")
+	checkState(t, err != nil, "full character names are not supported")
+	_, err = Parse("\\N{U+D800}")
+	checkState(t, err != nil, "should reject a surrogate")
+
+	r, err = Parse("\\x{D800}")
+	checkState(t, err != nil && r == nil, "should reject a surrogate")
+	r, err = Parse("\\x{DFFF}")
+	checkState(t, err != nil && r == nil, "should reject a surrogate")
+	r, err = Parse("\\x{FFFFFFFF}")
+	checkState(t, err != nil && r == nil, "should reject an out of range rune")
+	r, err = Parse("\\x{110000}")
+	checkState(t, err != nil && r == nil, "should reject an out of range rune")
+
+	r = MustParse("^\\x{E000}\\x{10FFFF}$")
+	checkState(t, r.Match("\ue000\U0010ffff"), "should match the edges of the valid range")
+
+	r, err = Parse("^\\Π$")
 	checkState(t, err != nil && r == nil,
 		"should have failed on trying to escape Π, not punctuation")
 }
@@ -166,9 +262,54 @@ func TestStringLiteral(t *testing.T) {
 	checkState(t, r.Match(".$\\"), "should match")
 	checkState(t, !r.Match(" $\\"), "should not match")
 
-//	r = MustParse("^a\\Q\\E*b$") // match absolutely nothing between 'ab'
-//	checkState(t, r.Match("ab"), "should match")
-//	checkState(t, !r.Match("acb"), "should not match")
+	r = MustParse("^\\Q.*+?\\E$")
+	checkState(t, r.Match(".*+?"), "metacharacters should match literally")
+	checkState(t, !r.Match("abc") && !r.Match(""), "metacharacters should not act as such")
+
+	r = MustParse("\\Q[a](b)|c\\E")
+	checkState(t, r.Match("x[a](b)|cx"), "brackets and bars should match literally")
+	checkState(t, !r.Match("c"), "bar should not alternate")
+
+	r = MustParse("\\Qcafé\\E")
+	checkIntSlice(t, []int{3, 8}, r.MatchIndex("un café"), "multibyte literal should give byte offsets")
+	checkIntSlice(t, []int{3, 7}, r.FindRunesIndex([]int("un café")), "multibyte literal should give rune offsets")
+	checkState(t, !r.Match("cafe"), "should match rune by rune")
+	checkState(t, MustParse("^\\Qπ\\E+$").Match("πππ"), "closure should repeat the literal")
+	checkState(t, MustParse("(?i)^\\QCAFÉ\\E$").Match("café"), "multibyte literal should fold case")
+
+	r = MustParse("^a\\Q\\E*b$") // match absolutely nothing between 'ab'
+	checkState(t, r.Match("ab"), "should match")
+	checkState(t, !r.Match("acb"), "should not match")
+}
+
+// Test regexps whose loops are made only of empty steps, which cleanup() leaves
+// in place, and which compact the program heavily.
+func TestEmptyLoops(t *testing.T) {
+	for _, src := range []string{"\\Q\\E*", "(?:)+", "(?:(?:))*", "(?:(?:)|(?:))+?", "()*", "(?:\\b)*"} {
+		r, err := Parse("^" + src + "$")
+		checkState(t, err == nil, "should parse: "+src)
+		if err != nil {
+			continue
+		}
+		checkState(t, r.Match(""), "should match empty: "+src)
+		checkState(t, !r.Match("a"), "should not match a rune: "+src)
+	}
+
+	r := MustParse("^(?:\\Q\\E)*a(?:)+b$")
+	checkState(t, r.Match("ab"), "empty loops should be skipped")
+	checkState(t, !r.Match("aab"), "empty loops should not consume")
+
+	r = MustParse("^(?:a|)*$")
+	checkState(t, r.Match("") && r.Match("aaa"), "loop with an empty branch should repeat")
+	checkState(t, !r.Match("ab"), "loop with an empty branch should not consume b")
+	r = MustParse("^(?:a|\\Q\\E)+b$")
+	checkState(t, r.Match("b") && r.Match("aab"), "loop with an empty literal branch should repeat")
+
+	r = MustParse("x(())*y")
+	checkState(t, r.Match("axyz") && !r.Match("axzy"), "repeated empty groups should be skipped")
+
+	s := NewStream(MustParse("(?:\\Q\\E)*ab"))
+	checkState(t, !s.Feed('a') && s.Feed('b'), "stream should step over empty loops")
 }
 
 // Test closure expansion types, such as {..}, ?, +, * etc.
@@ -220,6 +361,65 @@ func TestLeftRight(t *testing.T) {
 	checkState(t, r.Match(" a"), "right char is word")
 	checkState(t, !r.Match("  "), "not a boundary")
 	checkState(t, !r.Match("aa"), "not a boundary")
+
+	r = MustParse("\\<cat\\>")
+	checkState(t, r.Match("cat"), "whole string is the word")
+	checkState(t, r.Match("the cat sat"), "should match the word cat")
+	checkState(t, r.Match("(cat)"), "punctuation should delimit words")
+	checkState(t, !r.Match("category"), "should not match within category")
+	checkState(t, !r.Match("bobcat"), "should not match at the end of bobcat")
+
+	r = MustParse("^a\\>")
+	checkState(t, r.Match("a b"), "end of word before space")
+	checkState(t, !r.Match("ab"), "not the end of word")
+
+	r = MustParse("\\<\\d+\\>")
+	res := r.MatchIndex("ab 42c 17")
+	checkIntSlice(t, []int{7, 9}, res, "should only match the whole number")
+}
+
+// Test user dots next to the ".*?" which Parse wraps around every regexp, and
+// next to anchors.
+func TestDotAnchors(t *testing.T) {
+	r := MustParse(".abc")
+	checkState(t, r.Match("xabc"), "dot should take the rune before abc")
+	checkState(t, !r.Match("abc"), "dot should need a rune of its own")
+	checkIntSlice(t, []int{1, 5}, r.MatchIndex("zxabc"), "prefix should not take the dot's rune")
+
+	r = MustParse("^.abc")
+	checkState(t, r.Match("xabc"), "dot should be the first rune")
+	checkState(t, !r.Match("xxabc"), "abc should be at position 1")
+	checkState(t, !r.Match("\nabc"), "dot should not match a leading newline")
+	checkIntSlice(t, []int{0, 4}, r.MatchIndex("xabcabc"), "should match at the start")
+
+	r = MustParse("a.$")
+	checkState(t, r.Match("xab"), "dot should be the final rune")
+	checkState(t, !r.Match("a\n"), "dot should not match a final newline")
+	checkState(t, !r.Match("abc"), "dot should be directly before the end")
+	checkIntSlice(t, []int{3, 5}, r.MatchIndex("abcab"), "suffix should not take the dot's rune")
+	checkState(t, MustParse("(?s)a.$").Match("a\n"), "dotAll should match a final newline")
+
+	r = MustParse("^.$")
+	checkState(t, r.Match("π"), "single multibyte rune should match")
+	checkState(t, !r.Match("") && !r.Match("ab") && !r.Match("\n"), "should need exactly one non-newline rune")
+	checkState(t, MustParse("(?m)^.$").Match("\na\n"), "multiline should find the middle line")
+}
+
+// Test greedy and lazy closures which end the user's regexp, directly before
+// the lazy ".*?" suffix which Parse adds.
+func TestTrailingClosure(t *testing.T) {
+	r := MustParse("a.*")
+	checkIntSlice(t, []int{1, 5}, r.MatchIndex("xabca"), "greedy star should run to the end")
+	checkIntSlice(t, []int{1, 3}, r.MatchIndex("xab\ncd"), "greedy star should stop at a newline")
+	r = MustParse("a(.*)")
+	checkIntSlice(t, []int{1, 4, 2, 4}, r.MatchIndex("xabc"), "group should end with the star")
+
+	checkIntSlice(t, []int{1, 2}, MustParse("a.*?").MatchIndex("xabca"), "lazy star should stay minimal")
+	checkIntSlice(t, []int{1, 2, 2, 2}, MustParse("a(.*?)").MatchIndex("xabc"), "lazy group should be empty")
+	checkIntSlice(t, []int{1, 4}, MustParse("a+").MatchIndex("xaaab"), "greedy plus should take every a")
+	checkIntSlice(t, []int{1, 2}, MustParse("a+?").MatchIndex("xaaab"), "lazy plus should take one a")
+	checkIntSlice(t, []int{1, 3}, MustParse("ab?").MatchIndex("xabb"), "greedy option should take b")
+	checkIntSlice(t, []int{1, 2}, MustParse("ab??").MatchIndex("xabb"), "lazy option should skip b")
 }
 
 // Test general flags in sre2.
@@ -238,38 +438,278 @@ func TestFlags(t *testing.T) {
 	checkState(t, r.Match("AAaaAAaabbbbb"), "success")
 	checkState(t, !r.Match("AAaaAAaaBBBa"), "should fail, flag should not escape")
 
+	r = MustParse("^a(?i)bc$")
+	checkState(t, r.Match("aBC"), "flag should apply from where it appears")
+	checkState(t, r.Match("abc"), "flag should still allow lowercase")
+	checkState(t, !r.Match("ABC"), "flag should not apply before it appears")
+
+	r = MustParse("^(a(?i)b)c$")
+	checkState(t, r.Match("aBc"), "flag should apply to the rest of the group")
+	checkState(t, !r.Match("aBC"), "flag should end with the group")
+
+	r = MustParse("^a(?i)b(?-i)c$")
+	checkState(t, r.Match("aBc"), "flag should apply to b")
+	checkState(t, !r.Match("aBC"), "flag should be cleared for c")
+	checkState(t, !r.Match("ABc"), "flag should not apply to a")
+
+	r = MustParse("^(?i)a(?-i)b(?i)c$")
+	checkState(t, r.Match("AbC"), "flag should be set again for c")
+	checkState(t, !r.Match("ABC"), "flag should stay cleared for b")
+
+	r = MustParse("^(a(?-i)b)c$")
+	checkState(t, r.Match("abc"), "clearing an unset flag should do nothing")
+	r = MustParse("^(?i)(a(?-i)b)c$")
+	checkState(t, r.Match("Abc") && r.Match("AbC"), "flag should be restored after the group")
+	checkState(t, !r.Match("ABc"), "flag should be cleared within the group")
+
+	r = MustParse("^(?:x(?i)y|z)$")
+	checkState(t, r.Match("xY"), "flag should apply within its branch")
+	checkState(t, r.Match("Z"), "flag should carry into later branches, as in Perl")
+
+	r = MustParse("^(?i)[a-z]+$")
+	checkState(t, r.Match("AbC"), "class should match either case")
+	checkState(t, !r.Match("Ab1"), "class should not match digits")
+
+	r = MustParse("^(?i)[^aeiou]+$")
+	checkState(t, r.Match("BcD"), "consonants should match")
+	checkState(t, !r.Match("A"), "folded vowel should be negated")
+	checkState(t, !r.Match("e"), "vowel should be negated")
+
+	r = MustParse("^(?i)[^a-z]$")
+	checkState(t, !r.Match("A"), "uppercase should be negated after folding")
+	checkState(t, r.Match("1"), "digit should match")
+
+	r = MustParse("^(?i)\\Qab\\E$")
+	checkState(t, r.Match("aB"), "literal should be case-insensitive")
+
 	r = MustParse("(?s)^abc$.^def$")
 	checkState(t, !r.Match("abc\ndef"), "multiline mode not on by default")
 	r = MustParse("(?ms)^abc$.^def$")
 	checkState(t, r.Match("abc\ndef"), "multiline mode works as expected")
 }
 
+// Test reading back the flags in effect at the top level.
+func TestFlagsAccessor(t *testing.T) {
+	checkState(t, MustParse("(?i)abc").Flags() == "i", "leading flag should be in effect")
+	checkState(t, MustParse("(?ms)a(?i)b").Flags() == "ims", "flags should be sorted")
+	checkState(t, MustParse("(?i)a(?-i)b").Flags() == "", "cleared flag should not be in effect")
+	checkState(t, MustParse("(?i:a)b").Flags() == "", "group flag should not be in effect")
+	checkState(t, MustParse("abc").Flags() == "", "no flags should be in effect")
+	r, _ := ParseWithDotMode("(?U)a", true, false)
+	checkState(t, r.Flags() == "Us", "dotAll should imply s")
+}
+
+// Test the plain English description of compiled regexps.
+func TestExplain(t *testing.T) {
+	e := MustParse("^\\d+$").Explain()
+	checkState(t, strings.Contains(e, "start of text"), "should mention start anchor: "+e)
+	checkState(t, strings.Contains(e, "a digit, one or more times"), "should mention digits: "+e)
+	checkState(t, strings.Contains(e, "end of text"), "should mention end anchor: "+e)
+
+	e = MustParse("[a-z]x?y*").Explain()
+	checkState(t, e == "one of [a-z], then 'x', optionally, then 'y', zero or more times", "unexpected explanation: "+e)
+	e = MustParse("(a)").Explain()
+	checkState(t, e == "start of group 1, then 'a', then end of group 1", "unexpected explanation: "+e)
+	checkState(t, MustParse("").Explain() == "the empty string", "empty re should explain as such")
+}
+
+// Test the exported view of a compiled program.
+func TestProgram(t *testing.T) {
+	r := MustParse("^(?P<x>a)$")
+	prog := r.Program()
+	matches := 0
+	for idx, i := range prog {
+		checkState(t, i.Index == idx, "instructions should be in order")
+		checkState(t, i.Out < len(prog) && i.Out1 < len(prog), "outs should be in range")
+		if i.Mode == "iMatch" {
+			matches++
+		}
+	}
+	checkState(t, matches == 1, "should have a single match instruction")
+
+	// Follow the user's regexp from the outer capture.
+	var at Instruction
+	for _, i := range prog {
+		if i.Mode == "iIndexCap" && i.Cid == 0 {
+			at = prog[i.Out]
+		}
+	}
+	path := make([]string, 0)
+	for at.Mode != "iIndexCap" || at.Cid != 1 {
+		path = append(path, fmt.Sprint(at.Mode, " ", at.Boundary, at.Rune, at.Cid, at.Cname))
+		at = prog[at.Out]
+	}
+	checkCapture(t, []string{"iBoundaryCase bBeginText-1", "iIndexCap 2x", "iRuneClass 'a'-1",
+		"iIndexCap 3x", "iBoundaryCase bEndText-1"}, path, "should walk the user's regexp")
+
+	prog[0].Out = len(prog)
+	checkState(t, r.Program()[0].Out != len(prog), "should return a copy")
+}
+
+// Test finding the longest match at a fixed position.
+func TestLongestMatchAt(t *testing.T) {
+	r := MustParse("a|ab|abc")
+	length, ok := r.LongestMatchAt("abcd", 0)
+	checkState(t, ok && length == 3, "should prefer the longest branch")
+	length, ok = r.LongestMatchAt("xabc", 1)
+	checkState(t, ok && length == 3, "should match from pos")
+	_, ok = r.LongestMatchAt("xabc", 0)
+	checkState(t, !ok, "should not match later than pos")
+	_, ok = r.LongestMatchAt("abc", 4)
+	checkState(t, !ok, "pos past the end should not match")
+
+	length, ok = MustParse("x*").LongestMatchAt("abc", 0)
+	checkState(t, ok && length == 0, "should allow an empty match")
+	length, ok = MustParse("π+").LongestMatchAt("aπππb", 1)
+	checkState(t, ok && length == 6, "length should be in bytes")
+	length, ok = MustParse("a+?").LongestMatchAt("aaa", 0)
+	checkState(t, ok && length == 3, "lazy closures should still give the longest")
+
+	_, ok = MustParse("^a").LongestMatchAt("aa", 1)
+	checkState(t, !ok, "start anchor should see the rune before pos")
+	length, ok = MustParse("\\bab").LongestMatchAt("x ab", 2)
+	checkState(t, ok && length == 2, "word boundary should see the rune before pos")
+	length, ok = MustParse("ab$").LongestMatchAt("xab", 1)
+	checkState(t, ok && length == 2, "end anchor should match at the end")
+}
+
+// Test that batch matching gives one result per input, in order.
+func TestMatchBatch(t *testing.T) {
+	r := MustParse("^a+b$")
+	inputs := []string{"ab", "b", "aaab", "aba", ""}
+	results := r.MatchBatch(inputs)
+	checkState(t, len(results) == len(inputs), "should give a result per input")
+	for i, src := range inputs {
+		checkState(t, results[i] == r.Match(src), fmt.Sprintf("result %d should agree with Match", i))
+	}
+	checkState(t, len(r.MatchBatch(nil)) == 0, "empty batch should give no results")
+
+	// Large enough to be split across goroutines.
+	inputs = make([]string, 1000)
+	for i := range inputs {
+		inputs[i] = strings.Repeat("a", i%7) + "b"
+	}
+	results = r.MatchBatch(inputs)
+	for i := range inputs {
+		checkState(t, results[i] == (i%7 != 0), fmt.Sprintf("large batch result %d should keep order", i))
+	}
+}
+
+// Test that matching halts once its step limit is used up.
+func TestMatchWithFuel(t *testing.T) {
+	matched, exhausted := MustParse("^abc$").MatchWithFuel("abc", 100)
+	checkState(t, matched && !exhausted, "cheap re should match within its limit")
+	matched, exhausted = MustParse("^abc$").MatchWithFuel("abd", 100)
+	checkState(t, !matched && !exhausted, "cheap re should fail within its limit")
+
+	r := MustParse("(a|aa|aaa)*b")
+	src := strings.Repeat("a", 1000)
+	matched, exhausted = r.MatchWithFuel(src, 1000)
+	checkState(t, !matched && exhausted, "expensive re should exhaust its limit")
+	matched, exhausted = r.MatchWithFuel(src+"b", -1)
+	checkState(t, matched && !exhausted, "negative limit should not exhaust")
+}
+
+// Test patterns made up only of flag-setting groups.
+func TestFlagOnly(t *testing.T) {
+	r, err := Parse("(?i)")
+	checkState(t, err == nil, "flag-only re should parse")
+	checkState(t, r.NumSubexps() == 0, "flag-only re should have no alts")
+	checkState(t, r.Match(""), "flag-only re should match everything")
+	checkState(t, r.Match("fadsnjkflsdafnas"), "flag-only re should match everything")
+
+	r = MustParse("(?i)(?-i)(?ms)")
+	checkState(t, r.Match("abc"), "several flag groups should match everything")
+
+	r = MustParse("(?i)|abc")
+	checkState(t, r.Match(""), "empty branch should match everything")
+	checkState(t, r.Match("xyz"), "empty branch should match everything")
+
+	r = MustParse("^(?:(?i)|abc)$")
+	checkState(t, r.Match(""), "empty branch should match")
+	checkState(t, r.Match("ABC"), "flag should carry into later branches")
+	checkState(t, !r.Match("xyz"), "neither branch should match")
+
+	r = MustParse("^(?i)abc$|^xyz$")
+	checkState(t, r.Match("XYZ"), "top-level branches should share flags")
+	checkState(t, !r.Match("abcxyz"), "top-level branches are alternatives")
+
+	_, err = Parse("(?")
+	checkState(t, err != nil, "unterminated flag group must fail")
+}
+
+// Test control over what '.' matches.
+func TestDotMode(t *testing.T) {
+	r, err := ParseWithDotMode("^a.b$", false, true)
+	checkState(t, err == nil, "should parse")
+	checkState(t, r.Match("axb"), "should match a regular rune")
+	checkState(t, !r.Match("a\nb"), "should not match \\n")
+	checkState(t, !r.Match("a\rb"), "should not match \\r")
+	checkState(t, r.Match("a\tb"), "should match other control runes")
+
+	r, _ = ParseWithDotMode("^a.b$", false, false)
+	checkState(t, r.Match("a\rb"), "should match \\r by default")
+	checkState(t, !r.Match("a\nb"), "should not match \\n by default")
+
+	r, _ = ParseWithDotMode("^a.b$", true, false)
+	checkState(t, r.Match("a\nb"), "dotAll should match \\n")
+
+	r, _ = ParseWithDotMode("^a.(?-s).$", true, true)
+	checkState(t, r.Match("a\nx"), "dotAll should act as a leading (?s)")
+	checkState(t, !r.Match("a\n\r"), "clearing s should still exclude \\r")
+
+	r, _ = ParseWithDotMode("^(.+)\r$", false, true)
+	checkIntSlice(t, []int{0, 5, 0, 4}, r.MatchIndex("line\r"), "should leave \\r for the pattern")
+}
+
 // Test the behaviour of rune filters.
 func TestRuneFilter(t *testing.T) {
 	var filter RuneFilter
 
-	filter = matchRune('#')
+	filter = MatchRune('#')
 	checkState(t, !filter('B'), "should not match random rune")
 	checkState(t, filter('#'), "should match configured rune")
 
-	filter = matchRuneRange('A', 'Z')
+	filter = MatchRuneRange('A', 'Z')
 	checkState(t, filter('A'), "should match rune 'A' in range")
 	checkState(t, filter('B'), "should match rune 'B' in range")
 	checkState(t, !filter('a'), "should not match rune 'a', is lowercase")
 
-	filter = filter.ignoreCase()
+	filter = filter.IgnoreCase()
 	checkState(t, filter('a'), "should match rune 'a', case ignored")
 	checkState(t, filter('A'), "should still match rune 'A', case ignored")
 
-	filter = matchUnicodeClass("Greek")
+	filter = MatchUnicodeClass("Greek")
 	checkState(t, filter('Ω'), "should match omega")
 	checkState(t, !filter('Z'), "should not match regular latin rune")
 
-	filter = matchUnicodeClass("Cyrillic").not()
+	filter = MatchUnicodeClass("Cyrillic").Not()
 	checkState(t, filter('%'), "should match a random non-Cyrillic rune")
 	checkState(t, !filter('Ӄ'), "should not match Cyrillic rune")
 }
 
+// Test merging of literal runes and ranges within classes.
+func TestMergeRanges(t *testing.T) {
+	merged := mergeRanges([]runeRange{{'c', 'c'}, {'a', 'a'}, {'f', 'f'}, {'b', 'b'}, {'e', 'e'}, {'d', 'd'}})
+	checkState(t, len(merged) == 1 && merged[0].lo == 'a' && merged[0].hi == 'f', "abcdef should merge to a-f")
+
+	merged = mergeRanges([]runeRange{{'a', 'm'}, {'0', '9'}, {'k', 'z'}, {'_', '_'}})
+	checkState(t, len(merged) == 3, "should merge overlapping ranges only")
+	checkState(t, merged[0].lo == '0' && merged[0].hi == '9', "digits should sort first")
+	checkState(t, merged[1].lo == '_' && merged[1].hi == '_', "underscore should stay alone")
+	checkState(t, merged[2].lo == 'a' && merged[2].hi == 'z', "a-m and k-z should merge")
+
+	checkState(t, len(mergeRanges(nil)) == 0, "nothing to merge")
+
+	literal := MustParse("^[abcdef]$").(*sregexp)
+	ranged := MustParse("^[a-f]$").(*sregexp)
+	checkState(t, len(literal.prog) == len(ranged.prog), "should compile to the same program size")
+	for rune := 0; rune < 0x80; rune++ {
+		s := string(rune)
+		checkState(t, literal.Match(s) == ranged.Match(s), "should match the same runes: "+s)
+	}
+}
+
 // Test complex grouping configuration.
 func TestGroup(t *testing.T) {
 	r := MustParse("^(a)*$")
@@ -291,8 +731,361 @@ func TestGroupExtract(t *testing.T) {
 	r = MustParse("abc(def)g(h)ij(kl)?")
 	rv = r.Extract("abcdefghijkl", 4)
 	checkCapture(t, []string{"abcdefghijkl", "def", "h", "kl"}, rv, "should capture correct group")
+
+	// A group repeated by a closure keeps only its last iteration.
+	r = MustParse("(\\w)+")
+	rv = r.Extract("abc", 2)
+	checkCapture(t, []string{"abc", "c"}, rv, "should capture the last iteration")
+	r = MustParse("^(?:(a)|(b))+$")
+	rv = r.ExtractRange("aab", 1, 2)
+	checkCapture(t, []string{"a", "b"}, rv, "each group should keep its own last iteration")
+}
+
+// Test capturing groups into a reused buffer.
+func TestGroupExtractInto(t *testing.T) {
+	r := MustParse("abc(def)g(h)ij(kl)?")
+	buf := make([]string, 0, 4)
+	rv := r.ExtractInto("abcdefghijkl", buf)
+	checkCapture(t, []string{"abcdefghijkl", "def", "h", "kl"}, rv, "should capture every group")
+	checkState(t, &rv[0] == &buf[:1][0], "should reuse the buffer")
+	rv = r.ExtractInto("abcdefghij", rv[:0])
+	checkCapture(t, []string{"abcdefghij", "def", "h"}, rv, "should skip a missing group")
+	rv = r.ExtractInto("xyz", rv[:0])
+	checkState(t, len(rv) == 0, "should append nothing without a match")
+	rv = r.ExtractInto("abcdefghij", []string{"prior"})
+	checkCapture(t, []string{"prior", "abcdefghij", "def", "h"}, rv, "should append to dst")
+}
+
+// Test capturing a contiguous subset of groups.
+func TestGroupExtractRange(t *testing.T) {
+	r := MustParse("(a)(b)(c)(d)(e)")
+	rv := r.ExtractRange("xabcdex", 2, 3)
+	checkCapture(t, []string{"b", "c"}, rv, "should capture groups 2-3")
+	rv = r.ExtractRange("xabcdex", 0, 1)
+	checkCapture(t, []string{"abcde", "a"}, rv, "should capture whole match and group 1")
+	checkState(t, r.ExtractRange("abcd", 2, 3) == nil, "should not match")
+	checkState(t, r.ExtractRange("abcde", 4, 6) == nil, "range should be out of bounds")
+
+	r = MustParse("(a)(b)?(c)")
+	rv = r.ExtractRange("ac", 1, 3)
+	checkCapture(t, []string{"a", "", "c"}, rv, "missing group should be empty")
+}
+
+// Test locating a match by both byte and rune offsets.
+func TestFindResult(t *testing.T) {
+	res, ok := MustParse("é+b").FindResult("naïve ééb!")
+	checkState(t, ok, "should find a match")
+	if ok {
+		checkIntSlice(t, []int{7, 12}, []int{res.ByteStart, res.ByteEnd}, "byte offsets should count encoded bytes")
+		checkIntSlice(t, []int{6, 9}, []int{res.RuneStart, res.RuneEnd}, "rune offsets should count runes")
+		checkState(t, "naïve ééb!"[res.ByteStart:res.ByteEnd] == "ééb", "byte offsets should slice the match")
+	}
+
+	res, ok = MustParse("a*").FindResult("π")
+	checkState(t, ok && res.ByteStart == 0 && res.RuneEnd == 0, "should find an empty match at the start")
+	res, ok = MustParse("x").FindResult("abc")
+	checkState(t, !ok && res == nil, "should not find a match")
+}
+
+// Test retrieving group names, and captures by name.
+func TestSubexpNames(t *testing.T) {
+	r := MustParse("(?P<year>\\d{4})-(?P<month>\\d{2})")
+	checkCapture(t, []string{"", "year", "month"}, r.SubexpNames(), "should name groups by number")
+	named := r.ExtractNamed("on 2009-12")
+	checkState(t, len(named) == 2 && named["year"] == "2009" && named["month"] == "12",
+		fmt.Sprintf("should capture by name: %v", named))
+	checkState(t, r.ExtractNamed("2009") == nil, "should be nil without a match")
+
+	r = MustParse("(\\w+)(?:-(?P<tag>\\w+))?")
+	checkCapture(t, []string{"", "", "tag"}, r.SubexpNames(), "unnamed groups should be blank")
+	named = r.ExtractNamed("abc")
+	v, ok := named["tag"]
+	checkState(t, len(named) == 1 && ok && v == "", "missing named group should be blank")
+	checkCapture(t, []string{""}, MustParse("abc").SubexpNames(), "should name only the whole match")
+}
+
+// Test finding every match with its groups, by text and by offsets.
+func TestFindAll(t *testing.T) {
+	r := MustParse("(\\w)(\\d)")
+	rv := r.FindAll("a1b2c3", -1)
+	checkState(t, len(rv) == 3, "should find three pairs")
+	if len(rv) == 3 {
+		checkCapture(t, []string{"a1", "a", "1"}, rv[0], "should capture first pair")
+		checkCapture(t, []string{"b2", "b", "2"}, rv[1], "should capture second pair")
+		checkCapture(t, []string{"c3", "c", "3"}, rv[2], "should capture third pair")
+	}
+	checkState(t, len(r.FindAll("a1b2c3", 2)) == 2, "should stop after n matches")
+
+	index := r.FindAllIndex("a1b2c3", -1)
+	checkState(t, len(index) == 3, "should find three offsets")
+	if len(index) == 3 {
+		checkIntSlice(t, []int{0, 2, 0, 1, 1, 2}, index[0], "should locate first pair")
+		checkIntSlice(t, []int{2, 4, 2, 3, 3, 4}, index[1], "should locate second pair")
+		checkIntSlice(t, []int{4, 6, 4, 5, 5, 6}, index[2], "should locate third pair")
+	}
+	checkState(t, r.FindAllIndex("abc", -1) == nil, "should be nil without a match")
+	checkIntSlice(t, []int{1, 2, -1, -1}, MustParse("b(x)?").FindAllIndex("abc", -1)[0], "missing groups should be -1")
+}
+
+// Test capturing the groups of every match.
+func TestFindAllSubmatch(t *testing.T) {
+	r := MustParse("(\\w+)=(\\w+)")
+	rv := r.FindAllSubmatch("a=1&bb=22&c=x3", -1)
+	checkState(t, len(rv) == 3, "should find three pairs")
+	if len(rv) == 3 {
+		checkCapture(t, []string{"a=1", "a", "1"}, rv[0], "should capture first pair")
+		checkCapture(t, []string{"bb=22", "bb", "22"}, rv[1], "should capture second pair")
+		checkCapture(t, []string{"c=x3", "c", "x3"}, rv[2], "should capture third pair")
+	}
+	checkState(t, len(r.FindAllSubmatch("a=1&bb=22&c=x3", 2)) == 2, "should stop after n matches")
+	checkState(t, r.FindAllSubmatch("a&b", -1) == nil, "should be nil without a match")
+
+	r = MustParse("^(a)")
+	checkState(t, len(r.FindAllSubmatch("aaa", -1)) == 1, "later matches should see the previous rune")
+
+	r = MustParse("a*")
+	rv = r.FindAllSubmatch("baaac", -1)
+	checkState(t, len(rv) == 3, "should advance over empty matches")
+	if len(rv) == 3 {
+		checkCapture(t, []string{"", "aaa", ""}, []string{rv[0][0], rv[1][0], rv[2][0]},
+			"should skip the empty match after aaa")
+	}
+}
+
+// Test counting the matches each group took part in.
+func TestGroupStats(t *testing.T) {
+	r := MustParse("(\\w+)(=\\w+)?")
+	checkIntSlice(t, []int{4, 4, 2}, r.GroupStats("a=1 b c=3 d", -1), "optional group should count twice")
+	checkIntSlice(t, []int{2, 2, 1}, r.GroupStats("a=1 b c=3 d", 2), "should stop after n matches")
+	checkIntSlice(t, []int{0, 0, 0}, r.GroupStats("!?", -1), "no match should count nothing")
+}
+
+// Test Find and ReplaceFirst over the first match, and Find and Replace over all
+// with Global.
+func TestGlobal(t *testing.T) {
+	r := MustParse("o+")
+	checkState(t, r.ReplaceFirst("foo boo", "0") == "f0 boo", "should replace the first match")
+	checkState(t, r.Global().Replace("foo boo", "0") == "f0 b0", "global should replace every match")
+	checkState(t, r.Global().Replace("xyz", "0") == "xyz", "should leave src alone without a match")
+	checkCapture(t, []string{"oo"}, r.Find("foo boo"), "should find the first match")
+	checkCapture(t, []string{"oo", "oo"}, r.Global().Find("foo boo"), "global should find every match")
+	checkState(t, r.Find("xyz") == nil, "should be nil without a match")
+
+	r = MustParse("x*")
+	checkState(t, r.Global().Replace("ab", "-") == "-a-b-", "should replace empty matches")
+}
+
+// Test replacing only the leftmost match, or every match.
+func TestReplaceFirst(t *testing.T) {
+	r := MustParse("\\d+")
+	checkState(t, r.ReplaceFirst("a1b2c3", "#") == "a#b2c3", "later digits should be untouched")
+	checkState(t, r.ReplaceFirst("a12b", "") == "ab", "should replace the whole leftmost match")
+	checkState(t, r.ReplaceFirst("abc", "#") == "abc", "should leave src alone without a match")
+	checkState(t, r.ReplaceAll("a1b2c3", "#") == "a#b#c#", "should replace every match")
+}
+
+// Test that ReplaceAll expands group references, unlike ReplaceAllLiteral.
+func TestReplaceAll(t *testing.T) {
+	r := MustParse("(\\d{4})-(\\d{2})-(\\d{2})")
+	checkState(t, r.ReplaceAll("2009-12-31", "$3/$2/$1") == "31/12/2009", "should expand numbered groups")
+	checkState(t, r.ReplaceAll("from 2009-12-31 to 2010-01-02", "$3.$2.$1") == "from 31.12.2009 to 02.01.2010",
+		"should expand each match")
+	checkState(t, r.ReplaceAll("2009-12-31", "${1}0101") == "20090101", "braces should end the reference")
+	checkState(t, r.ReplaceAllLiteral("2009-12-31", "$1") == "$1", "literal replacement should not expand")
+	checkState(t, r.Global().Replace("2009-12-31", "$1") == "$1", "global replace should stay literal")
+
+	r = MustParse("(?P<year>\\d{4})-(?P<month>\\d{2})-(?P<day>\\d{2})")
+	checkState(t, r.ReplaceAll("2009-12-31", "${day}/${month}/${year}") == "31/12/2009", "should expand named groups")
+	checkState(t, r.ReplaceAll("2009-12-31", "$$") == "$", "$$ should give a dollar")
+}
+
+// Test replacing each match with a computed string.
+func TestReplaceAllFunc(t *testing.T) {
+	r := MustParse("[aeiou]+")
+	calls := make([]string, 0)
+	result := r.ReplaceAllFunc("beautiful queue", func(match string) string {
+		calls = append(calls, match)
+		return strings.ToUpper(match)
+	})
+	checkState(t, result == "bEAUtIfUl qUEUE", "should uppercase every vowel run: "+result)
+	checkState(t, strings.Join(calls, ",") == "eau,i,u,ueue", "should call f on each match in order")
+	checkState(t, r.ReplaceAllFunc("rhythm", strings.ToUpper) == "rhythm", "should leave src alone without a match")
+	checkState(t, MustParse("\\$").ReplaceAllFunc("$1", func(string) string { return "$$" }) == "$$1",
+		"result of f should not be expanded")
+}
+
+// Test splitting around matches, as regexp.Split.
+func TestSplit(t *testing.T) {
+	cases := []struct {
+		re, src  string
+		n        int
+		expected []string
+	}{
+		{",", "a,b,,c", -1, []string{"a", "b", "", "c"}},
+		{",", "a,b,,c", 2, []string{"a", "b,,c"}},
+		{",", "a,b,,c", 1, []string{"a,b,,c"}},
+		{",", ",a,", -1, []string{"", "a", ""}},
+		{",", "abc", -1, []string{"abc"}},
+		{",", "", -1, []string{""}},
+		{"x*", "abc", -1, []string{"a", "b", "c"}},
+		{"x*", "axxbc", -1, []string{"a", "b", "c"}},
+		{"x*", "πé", -1, []string{"π", "é"}},
+		{"x*", "abc", 2, []string{"a", "bc"}},
+	}
+	for _, c := range cases {
+		result := MustParse(c.re).Split(c.src, c.n)
+		checkState(t, fmt.Sprint(result) == fmt.Sprint(c.expected) && len(result) == len(c.expected),
+			fmt.Sprintf("%q over %q, n=%d: got %q, expected %q", c.re, c.src, c.n, result, c.expected))
+	}
+	checkState(t, MustParse(",").Split("a,b", 0) == nil, "n of 0 should give nil")
+}
+
+// Test replacing with templates referring to numbered and named groups.
+func TestReplaceAllTemplate(t *testing.T) {
+	r := MustParse("(?P<y>\\d{4})-(?P<m>\\d{2})")
+	checkState(t, r.ReplaceAllTemplate("2020-05", "${m}/${y}") == "05/2020", "should expand named groups")
+	checkState(t, r.ReplaceAllTemplate("2020-05", "$2/$1") == "05/2020", "should expand numbered groups")
+	checkState(t, r.ReplaceAllTemplate("from 2020-05 to 2021-11", "${m}/${y}") == "from 05/2020 to 11/2021",
+		"should expand every match")
+	checkState(t, r.ReplaceAllTemplate("2020-05", "[$0] $$${d}") == "[2020-05] $", "should expand $0 and $$")
+
+	r = MustParse("(a)(x)?")
+	checkState(t, r.ReplaceAllTemplate("ab", "<$2$3>") == "<>b", "missing groups should be empty")
+}
+
+// Test replacing patterns which may match empty.
+func TestReplaceAllEmpty(t *testing.T) {
+	cases := []struct{ re, src, expected string }{
+		{"x*", "abc", "-a-b-c-"},
+		{"x?", "ab", "-a-b-"},
+		{"x*", "", "-"},
+		{"", "ab", "-a-b-"},
+		{"a*", "baaac", "-b-c-"},
+		{"a*", "aaa", "-"},
+		{"a*?", "aa", "-a-a-"},
+		{"x*", "πé", "-π-é-"},
+		{"^", "abc", "-abc"},
+		{"$", "abc", "abc-"},
+		{"(?m)^", "a\nb", "-a\n-b"},
+	}
+	for _, c := range cases {
+		result := MustParse(c.re).ReplaceAll(c.src, "-")
+		checkState(t, result == c.expected,
+			fmt.Sprintf("%q over %q: got %q, expected %q", c.re, c.src, result, c.expected))
+	}
+}
+
+// Test matching over pre-decoded runes.
+func TestMatchRunes(t *testing.T) {
+	r := MustParse("^caf(é)$")
+	runes := []int{'c', 'a', 'f', 'é'}
+	checkState(t, r.MatchRunes(runes), "should match runes")
+	checkState(t, !r.MatchRunes([]int{'c', 'a', 'f', 'e'}), "should not match")
+
+	r = MustParse("(\\pL+)!")
+	runes = []int{'π', 'ж', ' ', 'ñ', 'é', '!'}
+	checkIntSlice(t, []int{3, 6, 3, 5}, r.FindRunesIndex(runes), "should return rune offsets")
+	checkIntSlice(t, []int{5, 10, 5, 9}, r.MatchIndex("πж ñé!"), "byte offsets should differ")
+	checkIntSlice(t, nil, r.FindRunesIndex([]int{'a'}), "should return nil on failed match")
+
+	r = MustParse("(b)?c")
+	checkIntSlice(t, []int{1, 2, -1, -1}, r.FindRunesIndex([]int{'ä', 'c'}), "should keep -1 for missing groups")
+}
+
+// Test matching runes fed one at a time.
+func TestStream(t *testing.T) {
+	s := NewStream(MustParse("ab+c"))
+	ends := make([]int, 0)
+	for i, rune := range []int("xabbcxxabcabx") {
+		if s.Feed(rune) {
+			ends = append(ends, i+1)
+			checkState(t, s.End() == i+1, "end should follow the last rune of the match")
+		}
+	}
+	checkIntSlice(t, []int{5, 10}, ends, "should report each match as it ends")
+	checkState(t, !s.Close(), "nothing should be pending at close")
+
+	s = NewStream(MustParse("\\bfoo\\b"))
+	ends = ends[:0]
+	for i, rune := range []int("a foo foobar") {
+		if s.Feed(rune) {
+			ends = append(ends, i)
+		}
+	}
+	checkIntSlice(t, []int{5}, ends, "word boundary should wait for the next rune")
+	checkState(t, s.End() == 5, "match should end before the space")
+
+	s = NewStream(MustParse("^ab$"))
+	checkState(t, !s.Feed('a') && !s.Feed('b'), "end of text is not yet known")
+	checkState(t, s.Close(), "close should complete the match")
+	checkState(t, s.End() == 2, "match should end at the end of input")
+
+	s = NewStream(MustParse("^ab"))
+	checkState(t, !s.Feed('x') && !s.Feed('a') && !s.Feed('b'), "anchor should only match at the start")
+	checkState(t, s.End() == -1, "nothing should have matched")
+}
+
+// Test listing the runes which may begin a match.
+func TestFirstRunes(t *testing.T) {
+	runes, any := MustParse("abc").FirstRunes()
+	checkState(t, !any, "literal should not start with any rune")
+	checkIntSlice(t, []int{'a'}, runes, "literal should start with its first rune")
+
+	runes, any = MustParse("[xy]z").FirstRunes()
+	checkState(t, !any, "class should not start with any rune")
+	checkIntSlice(t, []int{'x', 'y'}, runes, "class should start with its runes")
+
+	runes, any = MustParse("^(?:b|a)+").FirstRunes()
+	checkState(t, !any, "anchored alt should not start with any rune")
+	checkIntSlice(t, []int{'a', 'b'}, runes, "should list every branch, in order")
+
+	runes, any = MustParse(".*a").FirstRunes()
+	checkState(t, any && runes == nil, "dot should start with any rune")
+
+	_, any = MustParse("a?").FirstRunes()
+	checkState(t, any, "empty match should start with any rune")
 }
 
+// Test detection of patterns whose matches are all matched by another.
+func TestIsSubset(t *testing.T) {
+	subsets := [][]string{
+		{"[a-c]+", "[a-z]+"}, {"^abc$", "abc"}, {"abc", "b"}, {"a|b", "[ab]"},
+		{"^\\d+$", "^[0-9]"}, {"x*", "y*"}, {"[a-z]+", "[a-z]+"},
+	}
+	for _, pair := range subsets {
+		checkState(t, MustParse(pair[0]).IsSubset(MustParse(pair[1])), pair[0]+" should be a subset of "+pair[1])
+	}
+
+	others := [][]string{
+		{"[a-z]+", "[a-c]+"}, {"[0-9]+", "[a-z]+"}, {"abc", "^abc$"}, {"x*", "a"},
+		{"\\bab", "^ab"}, {"(?i)a", "a"},
+	}
+	for _, pair := range others {
+		checkState(t, !MustParse(pair[0]).IsSubset(MustParse(pair[1])), pair[0]+" should not be a subset of "+pair[1])
+	}
+}
+
+// Test detection of patterns which may match at the same position.
+func TestCheckDisjoint(t *testing.T) {
+	patterns := []Re{MustParse("[a-z]+"), MustParse("[a-c]+"), MustParse("[0-9]+"), MustParse("\\d\\w")}
+	conflicts, err := CheckDisjoint(patterns)
+	checkState(t, err == nil, "should analyse patterns")
+	checkState(t, len(conflicts) == 2, fmt.Sprintf("should find two conflicts, got %v", conflicts))
+	if len(conflicts) == 2 {
+		c := conflicts[0]
+		checkState(t, c.A == 0 && c.B == 1 && c.Rune == 'a', "[a-z]+ and [a-c]+ should overlap on 'a'")
+		c = conflicts[1]
+		checkState(t, c.A == 2 && c.B == 3 && c.Rune == '0', "[0-9]+ and \\d\\w should overlap on '0'")
+	}
+
+	patterns = []Re{MustParse("^if"), MustParse("(?:x|y)z"), MustParse("[ \t]+")}
+	conflicts, err = CheckDisjoint(patterns)
+	checkState(t, err == nil && len(conflicts) == 0, "should be disjoint")
+
+	patterns = []Re{MustParse("a*"), MustParse("b")}
+	conflicts, err = CheckDisjoint(patterns)
+	checkState(t, err == nil && len(conflicts) == 1 && conflicts[0].Rune == -1, "empty match should conflict")
+}
 
 // Test the SafeParser used by much of the code.
 func TestStringParser(t *testing.T) {
@@ -0,0 +1,140 @@
+package sre2
+
+// Incremental matching. A Stream is fed one rune at a time, e.g. as a log file
+// grows, and reports whenever a match ends without re-scanning earlier input.
+// It steps the same thread set as run(), but keeps it between calls.
+
+// Stream matches a regexp against runes which are supplied one at a time.
+// Submatches are not tracked; only the position at which each match ends.
+type Stream struct {
+	re *sregexp
+
+	curr *stateList // rune class states waiting on the next rune
+	next *stateList // scratch list for the following position
+
+	// Boundary instrs reached at the current position which need to see the
+	// next rune (e.g. '$' or '\b') before they can be resolved.
+	parked *stateList
+
+	left    int  // the last rune fed, or -1 before any
+	pos     int  // number of runes fed so far
+	end     int  // rune offset at which the last reported match ended
+	matched bool // set by add() when the outermost group closes
+	closed  bool
+}
+
+// NewStream builds a Stream for the given regexp, positioned before any input.
+// Each match of re is reported once, in the order they end.
+func NewStream(re Re) *Stream {
+	r := re.(*sregexp)
+	s := &Stream{re: r, left: -1, end: -1}
+	s.curr = makeStateList(len(r.prog))
+	s.next = makeStateList(len(r.prog))
+	s.parked = makeStateList(len(r.prog))
+	s.add(s.curr, r.prog[r.start], -1, false)
+	return s
+}
+
+// Determine whether the given boundary mode depends on the rune to its right.
+func needsRight(lr boundaryMode) bool {
+	return lr != bBeginText && lr != bBeginLine
+}
+
+// add descends through split, capture and boundary instrs from st, placing
+// rune class instrs into the given list. The rune right of the current position
+// is only used if known is set; otherwise boundaries which need it are parked.
+// Reaching the end of the outermost group marks a match, rather than following
+// the ".*?" suffix, so that every later match is reported too.
+// Split instrs are placed into the list too, marking them as visited.
+func (s *Stream) add(o *stateList, st *instr, right int, known bool) {
+	switch st.mode {
+	case iSplit:
+		if o.put(st.idx, nil) {
+			return // already descended, as in addstate
+		}
+		s.add(o, st.out, right, known)
+		if st.out1 != nil {
+			s.add(o, st.out1, right, known)
+		}
+	case iIndexCap:
+		if st.cid == 1 {
+			s.matched = true
+			return
+		}
+		s.add(o, st.out, right, known)
+	case iBoundaryCase:
+		if !known && needsRight(st.lr) {
+			s.parked.put(st.idx, nil)
+		} else if st.matchBoundaryMode(s.left, right) {
+			s.add(o, st.out, right, known)
+		}
+	case iRuneClass:
+		o.put(st.idx, nil)
+	case iMatch:
+		s.matched = true
+	default:
+		panic("unexpected instr")
+	}
+}
+
+// Resolve any parked boundaries now that the rune to their right is known,
+// adding the states they lead to into s.curr. Returns true if this completes
+// a match at the current position.
+func (s *Stream) resolve(right int) bool {
+	s.matched = false
+	for _, st := range s.parked.states {
+		i := s.re.prog[st.idx]
+		if i.matchBoundaryMode(s.left, right) {
+			s.add(s.curr, i.out, right, true)
+		}
+	}
+	s.parked.clear()
+	if s.matched {
+		s.end = s.pos
+	}
+	return s.matched
+}
+
+// Feed consumes the next rune of input, returning true if a match has ended
+// since the last report. Usually this is a match ending with rune; but a match
+// whose end depends on what follows it, such as one ending in '$' or '\b', is
+// only reported once that following rune is fed (or on Close).
+func (s *Stream) Feed(rune int) bool {
+	if s.closed {
+		panic("feed after close")
+	}
+	late := s.resolve(rune)
+
+	// Step every waiting thread over this rune. The states reached lie after
+	// it, so boundaries there see it on their left.
+	s.matched = false
+	s.left = rune
+	for _, st := range s.curr.states {
+		i := s.re.prog[st.idx]
+		if i.match(rune) {
+			s.add(s.next, i.out, -1, false)
+		}
+	}
+	s.curr, s.next = s.next, s.curr
+	s.next.clear()
+	s.pos++
+
+	if s.matched {
+		s.end = s.pos
+		return true
+	}
+	return late
+}
+
+// Close marks the end of input, resolving boundaries at the final position.
+// Returns true if this completes a match. The Stream may not be fed again.
+func (s *Stream) Close() bool {
+	s.closed = true
+	return s.resolve(-1)
+}
+
+// End returns the rune offset, within all input fed so far, directly after the
+// last reported match. This is -1 if nothing has matched yet.
+func (s *Stream) End() int {
+	return s.end
+}
@@ -0,0 +1,68 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"fmt"
+)
+
+
+/**
+ * Summarise how names collapse under enc: the number of distinct codes,
+ * the size of the largest bucket and the average bucket size. Useful to
+ * tell whether an encoder over- or under-groups a dataset.
+ */
+func CodeStats(names []string, enc Encoder) (distinct int, largestBucket int, avgBucket float64) {
+	
+	index := BuildIndex(names, enc)
+	total := 0
+	
+	for _, bucket := range index {
+		total += len(bucket)
+		if len(bucket) > largestBucket {
+			largestBucket = len(bucket)
+		}
+	}
+	
+	distinct = len(index)
+	if distinct > 0 {
+		avgBucket = float64(total) / float64(distinct)
+	}
+	
+	return distinct, largestBucket, avgBucket
+}
+
+
+// The most examples VersionDrift reports.
+const maxDriftExamples = 5
+
+
+/**
+ * Compare the codes oldEnc and newEnc give names, counting how many of
+ * the total change. Up to maxDriftExamples of the changed names are
+ * given as examples, formatted "name: old -> new". Useful to judge the
+ * impact of migrating stored codes to a new algorithm or version.
+ */
+func VersionDrift(names []string, oldEnc, newEnc Encoder) (changed int, total int, examples []string) {
+	
+	for _, name := range names {
+		oldCode, newCode := oldEnc.Encode(name), newEnc.Encode(name)
+		if oldCode != newCode {
+			if changed < maxDriftExamples {
+				examples = append(examples, fmt.Sprintf("%s: %s -> %s", name, oldCode, newCode))
+			}
+			changed++
+		}
+	}
+	
+	return changed, len(names), examples
+}
@@ -0,0 +1,41 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestCodeStats(t *testing.T) {
+	distinct, largest, avg := CodeStats(indexNames, SoundexEncoder)
+	checkState(t, distinct == 4, "should have four distinct codes")
+	checkState(t, largest == 3, "largest bucket should hold three names")
+	checkState(t, avg == 1.75, "average bucket should be 7/4")
+
+	distinct, largest, avg = CodeStats(nil, SoundexEncoder)
+	checkState(t, distinct == 0 && largest == 0 && avg == 0, "empty input should give zeros")
+}
+
+func TestVersionDrift(t *testing.T) {
+	american := NewEncoder("soundex-american", func(text string) string {
+		return SoundexVariant(text, 4, SoundexAmerican)
+	})
+	names := []string{"Robert", "Ashcraft", "Tymczak", "Lee"}
+	changed, total, examples := VersionDrift(names, SoundexEncoder, american)
+	checkState(t, changed == 1, "only Ashcraft should change")
+	checkState(t, total == 4, "should count every name")
+	checkCapture(t, []string{"Ashcraft: A226 -> A261"}, examples, "should give the changed name")
+
+	changed, total, examples = VersionDrift(names, SoundexEncoder, SoundexEncoder)
+	checkState(t, changed == 0 && total == 4 && len(examples) == 0, "same encoder should not drift")
+}
@@ -0,0 +1,95 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"unicode"
+)
+
+
+/**
+ * Latin spellings used by Transliterate, per script as named by
+ * DetectScript, keyed by lower case letters or pairs of letters.
+ * Cyrillic follows the ASCII form of ISO 9 (its System B), dropping
+ * the hard and soft signs and folding ë to e, so Горбачёв becomes
+ * Gorbachev. Greek follows ELOT 743, so Παπαδόπουλος becomes
+ * Papadopoulos. Adding a script here makes Transliterate support it.
+ */
+var Transliterations = map[string]map[string]string{
+	"cyrillic": {
+		"а": "a", "б": "b", "в": "v", "г": "g", "д": "d", "е": "e", "ё": "e",
+		"ж": "zh", "з": "z", "и": "i", "й": "j", "к": "k", "л": "l", "м": "m",
+		"н": "n", "о": "o", "п": "p", "р": "r", "с": "s", "т": "t", "у": "u",
+		"ф": "f", "х": "x", "ц": "c", "ч": "ch", "ш": "sh", "щ": "shh", "ъ": "",
+		"ы": "y", "ь": "", "э": "e", "ю": "yu", "я": "ya",
+		"є": "ye", "і": "i", "ї": "yi", "ґ": "g", "ў": "u",
+	},
+	"greek": {
+		"ου": "ou", "ού": "ou", "αυ": "av", "αύ": "av", "ευ": "ev", "εύ": "ev",
+		"α": "a", "ά": "a", "β": "v", "γ": "g", "δ": "d", "ε": "e", "έ": "e",
+		"ζ": "z", "η": "i", "ή": "i", "θ": "th", "ι": "i", "ί": "i", "ϊ": "i",
+		"ΐ": "i", "κ": "k", "λ": "l", "μ": "m", "ν": "n", "ξ": "x", "ο": "o",
+		"ό": "o", "π": "p", "ρ": "r", "σ": "s", "ς": "s", "τ": "t", "υ": "y",
+		"ύ": "y", "ϋ": "y", "ΰ": "y", "φ": "f", "χ": "ch", "ψ": "ps", "ω": "o",
+		"ώ": "o",
+	},
+}
+
+
+/**
+ * Spell s in Latin letters, using the table in Transliterations for
+ * the dominant script of s (see DetectScript), so that Cyrillic and
+ * Greek names can be coded by the Latin encoders of this package.
+ * Pairs of letters are matched before single ones; runes not in the
+ * table, e.g. Latin letters or spaces, are kept. A capital gives a
+ * capitalised spelling. Returns s unchanged for unsupported scripts.
+ */
+func Transliterate(s string) string {
+	
+	table, ok := Transliterations[DetectScript(s)]
+	if !ok {
+		return s
+	}
+	
+	runes := []int(s)
+	rv := make([]int, 0, len(runes))
+	
+	for i := 0; i < len(runes); {
+		matched := false
+		for n := 2; n > 0 && !matched; n-- {
+			if i+n > len(runes) {
+				continue
+			}
+			key := make([]int, n)
+			for j := range key {
+				key[j] = unicode.ToLower(runes[i+j])
+			}
+			latin, found := table[string(key)]
+			if !found {
+				continue
+			}
+			spelling := []int(latin)
+			if unicode.IsUpper(runes[i]) && len(spelling) > 0 {
+				spelling[0] = unicode.ToUpper(spelling[0])
+			}
+			rv = append(rv, spelling...)
+			i += n
+			matched = true
+		}
+		if !matched {
+			rv = append(rv, runes[i])
+			i++
+		}
+	}
+	
+	return string(rv)
+}
@@ -0,0 +1,29 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestTransliterate(t *testing.T) {
+	checkString(t, Transliterate("Горбачёв"), "Gorbachev", "should transliterate Cyrillic")
+	checkString(t, Transliterate("Чайковский"), "Chajkovskij", "should capitalise a digraph")
+	checkString(t, Transliterate("Παπαδόπουλος"), "Papadopoulos", "should transliterate Greek")
+	checkString(t, Transliterate("Θεοδωράκης"), "Theodorakis", "should drop accents")
+	checkString(t, Transliterate("Robert"), "Robert", "should keep Latin")
+	checkString(t, Transliterate("李"), "李", "should keep unsupported scripts")
+
+	checkString(t, Soundex(Transliterate("Горбачёв"), 4), Soundex("Gorbachev", 4), "Cyrillic should code as its Latin spelling")
+	checkString(t, Soundex(Transliterate("Παπαδόπουλος"), 4), Soundex("Papadopoulos", 4), "Greek should code as its Latin spelling")
+}
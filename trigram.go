@@ -0,0 +1,44 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+
+/**
+ * Encode name with enc and split the code into its distinct character
+ * trigrams, in order of first appearance. A code shorter than three
+ * characters is returned whole. Storing these in a trigram index gives
+ * fuzzy phonetic search.
+ */
+func PhoneticTrigrams(name string, enc Encoder) []string {
+	
+	code := enc.Encode(name)
+	
+	if code == "" {
+		return []string{}
+	}
+	if len(code) < 3 {
+		return []string{code}
+	}
+	
+	seen := make(map[string]bool)
+	rv := make([]string, 0, len(code)-2)
+	
+	for i := 0; i+3 <= len(code); i++ {
+		tri := code[i : i+3]
+		if !seen[tri] {
+			seen[tri] = true
+			rv = append(rv, tri)
+		}
+	}
+	
+	return rv
+}
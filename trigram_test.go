@@ -0,0 +1,24 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestPhoneticTrigrams(t *testing.T) {
+	checkCapture(t, []string{"R16", "163"}, PhoneticTrigrams("Robert", SoundexEncoder), "should split R163")
+	checkCapture(t, []string{"MA1", "A11", "111"}, PhoneticTrigrams("Mayer", CaverphoneEncoder), "should drop repeated trigrams")
+	checkCapture(t, []string{"67"}, PhoneticTrigrams("Meyer", CologneEncoder), "short code should be whole")
+	checkCapture(t, []string{}, PhoneticTrigrams("", SoundexEncoder), "empty code has no trigrams")
+}
@@ -0,0 +1,78 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"strings"
+)
+
+
+// Respellings tried by Variants, each as {from, to}.
+var variantRules = [][2]string{
+	{"ph", "f"}, {"f", "ph"}, {"ck", "k"}, {"k", "ck"}, {"c", "k"}, {"k", "c"},
+	{"i", "y"}, {"y", "i"}, {"ie", "y"}, {"ee", "ea"}, {"ea", "ee"},
+	{"ai", "ay"}, {"ay", "ai"}, {"ou", "ow"}, {"ow", "ou"},
+	{"th", "t"}, {"t", "th"}, {"s", "z"}, {"z", "s"},
+}
+
+
+/**
+ * Generate up to max respellings of name which share its code under
+ * enc, e.g. Smyth and Smithe for Smith, for query expansion. Each
+ * applies one rule of variantRules at one place, or adds or drops a
+ * final e. A max of zero or less means no limit.
+ */
+func Variants(name string, enc Encoder, max int) []string {
+	
+	code := enc.Encode(name)
+	lower := strings.ToLower(name)
+	title := len(name) > 0 && name[0] >= 'A' && name[0] <= 'Z'
+	
+	seen := map[string]bool{lower: true}
+	rv := make([]string, 0)
+	
+	try := func(v string) {
+		if seen[v] || (max > 0 && len(rv) >= max) {
+			return
+		}
+		seen[v] = true
+		if title && len(v) > 0 && v[0] >= 'a' && v[0] <= 'z' {
+			v = string(v[0]-'a'+'A') + v[1:]
+		}
+		if enc.Encode(v) == code {
+			rv = append(rv, v)
+		}
+	}
+	
+	for _, r := range variantRules {
+		from, to := r[0], r[1]
+		for i := 0; i+len(from) <= len(lower); i++ {
+			if lower[i:i+len(from)] != from {
+				continue
+			}
+			// skip places already spelled as a longer to, e.g. the t of "th"
+			if len(to) > len(from) && (strings.HasPrefix(lower[i:], to) ||
+				strings.HasSuffix(lower[:i+len(from)], to)) {
+				continue
+			}
+			try(lower[:i] + to + lower[i+len(from):])
+		}
+	}
+	
+	if strings.HasSuffix(lower, "e") {
+		try(lower[:len(lower)-1])
+	} else if lower != "" {
+		try(lower + "e")
+	}
+	
+	return rv
+}
@@ -0,0 +1,28 @@
+/**
+*
+* 	Phonetic library by Robin Syihab (r [at] nosql.asia)
+*
+*	License: MIT
+*
+*	Copyright (c) 2009 The Go Authors. All rights reserved.
+*
+**/
+
+
+package phonetic
+
+import (
+	"testing"
+)
+
+
+func TestVariants(t *testing.T) {
+	variants := Variants("Smith", SoundexEncoder, 0)
+	checkCapture(t, []string{"Smyth", "Smit", "Smithe"}, variants, "should respell Smith")
+	for _, v := range variants {
+		checkString(t, Soundex(v, 4), Soundex("Smith", 4), "variant should share the code: "+v)
+	}
+	checkCapture(t, []string{"Smyth"}, Variants("Smith", SoundexEncoder, 1), "should stop at max")
+	checkCapture(t, []string{"lea", "le"}, Variants("lee", SoundexEncoder, 0), "should keep lower case")
+	checkState(t, len(Variants("", SoundexEncoder, 0)) == 0, "blank name should give no variants")
+}